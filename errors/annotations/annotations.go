@@ -0,0 +1,221 @@
+// Package annotations writes the diagnostics in the formats of the CI systems
+// that show them inline on pull and merge requests.
+package annotations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bsido/go-errors/errors"
+)
+
+// annotation is an *Error or a plain error with the parts that are written by both formats.
+type annotation struct {
+	severity errors.Severity
+	code     string
+	message  string
+	label    *errors.Label
+}
+
+// WriteGitHub writes a GitHub Actions workflow command for every *Error in the trees of the diagnostics:
+//
+//	::error file=src/main.rs,line=4,col=16,title=E0277::'Foo' is not an iterator
+//
+// Warnings are ::warning, notes and infos are ::notice commands.
+// The location is the first primary label of the error, the notes and helps are the following lines of the message.
+func WriteGitHub(w io.Writer, diagnostics []error) error {
+	for _, a := range collect(diagnostics) {
+		var properties []string
+
+		if a.label != nil {
+			properties = append(properties,
+				"file="+escapeProperty(a.label.File),
+				fmt.Sprintf("line=%d", a.label.Line),
+				fmt.Sprintf("col=%d", a.label.Column),
+			)
+		}
+
+		if a.code != "" {
+			properties = append(properties, "title="+escapeProperty(a.code))
+		}
+
+		command := "::" + githubLevel(a.severity)
+		if len(properties) > 0 {
+			command += " " + strings.Join(properties, ",")
+		}
+
+		if _, err := fmt.Fprintf(w, "%s::%s\n", command, escapeData(a.message)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Issue is an entry of a GitLab Code Quality report.
+type Issue struct {
+	Description string `json:"description"`
+	CheckName   string `json:"check_name"`
+	Fingerprint string `json:"fingerprint"`
+	Severity    string `json:"severity"`
+	Location    struct {
+		Path  string `json:"path"`
+		Lines struct {
+			Begin int `json:"begin"`
+		} `json:"lines"`
+	} `json:"location"`
+}
+
+// CodeQuality returns the GitLab Code Quality issues of every *Error in the trees of the diagnostics.
+// The check name is the code of the error or the name of its severity if it has no code, e.g. "warning".
+// GitLab requires the path of the issues, the errors without labels and the diagnostics that are not *Error are skipped.
+func CodeQuality(diagnostics []error) []Issue {
+	result := make([]Issue, 0)
+
+	for _, a := range collect(diagnostics) {
+		if a.label == nil {
+			continue
+		}
+
+		issue := Issue{
+			Description: a.message,
+			CheckName:   a.code,
+			Severity:    gitlabSeverity(a.severity),
+		}
+
+		if issue.CheckName == "" {
+			issue.CheckName = checkName(a.severity)
+		}
+
+		issue.Location.Path = a.label.File
+		issue.Location.Lines.Begin = a.label.Line
+
+		// the same problem at the same location has the same fingerprint in every pipeline
+		hash := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%d", issue.CheckName, issue.Description,
+			issue.Location.Path, issue.Location.Lines.Begin)))
+		issue.Fingerprint = hex.EncodeToString(hash[:])
+
+		result = append(result, issue)
+	}
+
+	return result
+}
+
+// WriteGitLab writes the GitLab Code Quality report of the diagnostics as indented JSON.
+func WriteGitLab(w io.Writer, diagnostics []error) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(CodeQuality(diagnostics))
+}
+
+// collect returns the annotations of the diagnostics in depth-first order,
+// diagnostics that are not *Error are errors with their messages.
+func collect(diagnostics []error) []annotation {
+	var result []annotation
+
+	for _, diagnostic := range diagnostics {
+		if diagnostic == nil {
+			continue
+		}
+
+		var e *errors.Error
+		if !goerrors.As(diagnostic, &e) {
+			result = append(result, annotation{
+				severity: errors.SeverityError,
+				message:  diagnostic.Error(),
+			})
+
+			continue
+		}
+
+		errors.Walk(diagnostic, func(e *errors.Error) bool {
+			result = append(result, newAnnotation(e))
+			return true
+		})
+	}
+
+	return result
+}
+
+func newAnnotation(e *errors.Error) annotation {
+	message := e.Summary()
+
+	for _, note := range e.GetNotes() {
+		message += "\nnote: " + note
+	}
+
+	for _, help := range e.GetHelps() {
+		message += "\nhelp: " + help
+	}
+
+	result := annotation{
+		severity: e.Severity(),
+		code:     e.GetCode(),
+		message:  message,
+	}
+
+	for _, label := range e.Labels() {
+		if label.Primary {
+			result.label = &label
+			break
+		}
+	}
+
+	return result
+}
+
+func githubLevel(severity errors.Severity) string {
+	switch severity {
+	case errors.SeverityWarning:
+		return "warning"
+	case errors.SeverityNote, errors.SeverityInfo:
+		return "notice"
+	default:
+		return "error"
+	}
+}
+
+// checkName returns the stable name of the severity, the names of the severities are meant for the humans.
+func checkName(severity errors.Severity) string {
+	switch severity {
+	case errors.SeverityWarning:
+		return "warning"
+	case errors.SeverityNote:
+		return "note"
+	case errors.SeverityInfo:
+		return "info"
+	case errors.SeverityBug:
+		return "bug"
+	default:
+		return "error"
+	}
+}
+
+func gitlabSeverity(severity errors.Severity) string {
+	switch severity {
+	case errors.SeverityWarning:
+		return "minor"
+	case errors.SeverityNote, errors.SeverityInfo:
+		return "info"
+	case errors.SeverityBug:
+		return "critical"
+	default:
+		return "major"
+	}
+}
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes the value of a workflow command property.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,131 @@
+package annotations
+
+import (
+	"bytes"
+	"encoding/json"
+	goerrors "errors"
+	"io"
+	"testing"
+
+	"github.com/bsido/go-errors/errors"
+	"github.com/bsido/go-errors/warnings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WriteGitHub(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		errs     []error
+		expected string
+	}{
+		{
+			name: "error with a location",
+			errs: []error{
+				errors.New("'Foo' is not an iterator").
+					Code(277).
+					SecondaryLabel("src/lib.rs", 1, 1, 3, "").
+					Label("src/main.rs", 4, 16, 3, "'Foo' is not an iterator").
+					Note("maybe try calling '.iter()' or a similar method").
+					Help("the trait 'std::iter::Iterator' is not implemented for 'Foo'"),
+			},
+			expected: "::error file=src/main.rs,line=4,col=16,title=E0277::'Foo' is not an iterator" +
+				"%0Anote: maybe try calling '.iter()' or a similar method" +
+				"%0Ahelp: the trait 'std::iter::Iterator' is not implemented for 'Foo'\n",
+		},
+		{
+			name: "warnings and notes",
+			errs: []error{
+				warnings.New("unused variable").Code(1),
+				errors.New("the build is cached").SetSeverity(errors.SeverityNote),
+			},
+			expected: "::warning title=W0001::unused variable\n" +
+				"::notice::the build is cached\n",
+		},
+		{
+			name: "every error of the tree",
+			errs: []error{
+				errors.New("build failed").
+					Cause(io.EOF).
+					Wrap(warnings.New("unused variable").Label("src/main.rs", 2, 9, 1, "")),
+			},
+			expected: "::error::build failed: EOF\n" +
+				"::warning file=src/main.rs,line=2,col=9::unused variable\n",
+		},
+		{
+			name: "escaping",
+			errs: []error{
+				goerrors.New("100% done\r\n"),
+				errors.New("test").CodeString("A:1,2").Label("a,b:c.go", 1, 1, 1, ""),
+				nil,
+			},
+			expected: "::error::100%25 done%0D%0A\n" +
+				"::error file=a%2Cb%3Ac.go,line=1,col=1,title=A%3A1%2C2::test\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, WriteGitHub(&buf, tt.errs))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func Test_WriteGitLab(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteGitLab(&buf, []error{
+		errors.New("'Foo' is not an iterator").
+			Code(277).
+			Label("src/main.rs", 4, 16, 3, "").
+			Help("call '.iter()'").
+			Wrap(warnings.New("unused variable").Label("src/main.rs", 2, 9, 1, "")).
+			Wrap(warnings.New("without a location")),
+		errors.New("bug").SetSeverity(errors.SeverityBug).Label("src/lib.rs", 1, 1, 1, ""),
+		goerrors.New("plain"),
+	}))
+
+	var issues []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &issues))
+	require.Len(t, issues, 3)
+
+	for _, issue := range issues {
+		assert.Len(t, issue["fingerprint"], 64)
+		delete(issue, "fingerprint")
+	}
+
+	expected := `[
+		{
+			"description": "'Foo' is not an iterator\nhelp: call '.iter()'",
+			"check_name": "E0277",
+			"severity": "major",
+			"location": {"path": "src/main.rs", "lines": {"begin": 4}}
+		},
+		{
+			"description": "unused variable",
+			"check_name": "warning",
+			"severity": "minor",
+			"location": {"path": "src/main.rs", "lines": {"begin": 2}}
+		},
+		{
+			"description": "bug",
+			"check_name": "bug",
+			"severity": "critical",
+			"location": {"path": "src/lib.rs", "lines": {"begin": 1}}
+		}
+	]`
+
+	actual, marshalErr := json.Marshal(issues)
+	require.NoError(t, marshalErr)
+	assert.JSONEq(t, expected, string(actual))
+}
+
+func Test_CodeQuality_Fingerprint(t *testing.T) {
+	first := CodeQuality([]error{errors.New("test").Label("src/main.rs", 1, 1, 1, "")})
+	second := CodeQuality([]error{errors.New("test").Label("src/main.rs", 1, 1, 1, "")})
+	other := CodeQuality([]error{errors.New("test").Label("src/main.rs", 2, 1, 1, "")})
+
+	assert.Equal(t, first[0].Fingerprint, second[0].Fingerprint)
+	assert.NotEqual(t, first[0].Fingerprint, other[0].Fingerprint)
+
+	assert.Equal(t, []Issue{}, CodeQuality(nil))
+}
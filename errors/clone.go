@@ -0,0 +1,58 @@
+package errors
+
+import (
+	"maps"
+	"slices"
+)
+
+// Freeze makes the error immutable: the builder methods (Help, Note, Wrap, ...)
+// return a decorated copy instead of modifying it, the copies are frozen too.
+// Useful for package level errors that are decorated at the call sites:
+//
+//	var ErrNotFound = errors.New("not found").Code(404).Freeze()
+//
+//	return ErrNotFound.Helpf("create the user '%s' first", name)
+//
+// The copies match the original error with errors.Is.
+func (e *Error) Freeze() *Error {
+	e.frozen = true
+
+	return e
+}
+
+func (e *Error) IsFrozen() bool {
+	return e.frozen
+}
+
+// Clone returns a mutable copy of the error that matches the original error with errors.Is.
+// The wrapped errors and the cause are not copied.
+func (e *Error) Clone() *Error {
+	result := *e
+
+	result.helps = slices.Clone(e.helps)
+	result.notes = slices.Clone(e.notes)
+	result.snippets = slices.Clone(e.snippets)
+	result.labels = slices.Clone(e.labels)
+	result.suggestions = slices.Clone(e.suggestions)
+	result.wrapped = slices.Clone(e.wrapped)
+	result.stack = slices.Clone(e.stack)
+	result.additionalTemplateData = maps.Clone(e.additionalTemplateData)
+	result.parameters = maps.Clone(e.parameters)
+
+	result.frozen = false
+	result.origin = e
+
+	return &result
+}
+
+// mutable returns the error itself or a frozen copy of it if it is frozen.
+func (e *Error) mutable() *Error {
+	if !e.frozen {
+		return e
+	}
+
+	result := e.Clone()
+	result.frozen = true
+
+	return result
+}
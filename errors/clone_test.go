@@ -0,0 +1,90 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Freeze(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	sentinel := New("not found").Help("check the name").Freeze()
+
+	for _, tt := range []struct {
+		name     string
+		err      *Error
+		expected string
+	}{
+		{
+			name:     "help",
+			err:      sentinel.Help("create it first"),
+			expected: "error: not found\n   = help: check the name\n   = help: create it first",
+		},
+		{
+			name:     "note",
+			err:      sentinel.Note("note"),
+			expected: "error: not found\n   = note: note\n   = help: check the name",
+		},
+		{
+			name:     "wrap and cause",
+			err:      sentinel.Causef("cause").Wrap(New("wrapped")),
+			expected: "error: not found\n  --> cause\n   = help: check the name\n\nerror: wrapped",
+		},
+		{
+			name:     "code and severity",
+			err:      sentinel.Code(404).SetSeverity(SeverityWarning),
+			expected: "warning[E0404]: not found\n   = help: check the name",
+		},
+		{
+			name:     "extend with message",
+			err:      ExtendWithMessage(sentinel, "user not found"),
+			expected: "error: user not found\n   = help: check the name",
+		},
+		{
+			name:     "extend with an initializer",
+			err:      NewInitializer(WithTemplateDefinition(TemplateDefinitionHelps, `{{ define "helps" }}{{ end }}`)).Extend(sentinel),
+			expected: "error: not found",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.err.Error())
+			assert.NotSame(t, sentinel, tt.err)
+			assert.True(t, tt.err.IsFrozen())
+			assert.True(t, Is(tt.err, sentinel))
+			assert.False(t, Is(sentinel, tt.err))
+
+			// the sentinel is not modified
+			assert.Equal(t, "error: not found\n   = help: check the name", sentinel.Error())
+		})
+	}
+
+	// the copies of the copies match the original too
+	assert.True(t, Is(sentinel.Help("first").Note("second"), sentinel))
+	assert.True(t, Is(New("wrapper").Wrap(sentinel.Help("first")), sentinel))
+}
+
+func Test_Clone(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	err := New("test").Help("help").AdditionalTemplateData(map[string]any{"Key": "value"})
+	clone := err.Clone()
+
+	assert.False(t, clone.IsFrozen())
+	assert.Same(t, clone, clone.Help("other"))
+
+	clone.additionalTemplateData["Key"] = "other"
+
+	assert.Equal(t, "error: test\n   = help: help", err.Error())
+	assert.Equal(t, "error: test\n   = help: help\n   = help: other", clone.Error())
+	assert.Equal(t, "value", err.additionalTemplateData["Key"])
+	assert.True(t, Is(clone, err))
+
+	// errors that are not frozen are still modified in place
+	assert.Same(t, err, err.Help("other"))
+}
@@ -0,0 +1,46 @@
+package errors
+
+import (
+	"fmt"
+	"math"
+)
+
+// CodeFormat describes how the numeric error codes are rendered.
+type CodeFormat struct {
+	Prefix string
+	// Width is the number of digits the codes are padded to with zeros,
+	// codes with more digits are out of range. 0 means no padding and no limit.
+	Width int
+}
+
+// DefaultCodeFormat renders the codes like rustc: E0277.
+var DefaultCodeFormat = CodeFormat{Prefix: "E", Width: 4}
+
+// Format panics if the code is out of the range of the format.
+func (f CodeFormat) Format(code int) string {
+	if code < 0 || (f.Width > 0 && float64(code) >= math.Pow10(f.Width)) {
+		panic(fmt.Sprintf("number out of range: %d", code))
+	}
+
+	return fmt.Sprintf("%s%0*d", f.Prefix, f.Width, code)
+}
+
+// Code sets the error code formatted by the code format of the initializer.
+func (e *Error) Code(code int) *Error {
+	return e.CodeString(e.getInit().codeFormat.Format(code))
+}
+
+// CodeString sets the error code as it is, e.g. "AUTH-123".
+func (e *Error) CodeString(code string) *Error {
+	e = e.mutable()
+	e.code = code
+
+	e.getInit().registry.check(e.code)
+
+	return e
+}
+
+// GetCode returns the rendered error code.
+func (e *Error) GetCode() string {
+	return e.code
+}
@@ -0,0 +1,89 @@
+package errors
+
+import (
+	"log"
+	"maps"
+	"strings"
+	"text/template"
+)
+
+// Define declares a frozen error whose message, notes and helps are templates,
+// they are executed with the data passed to With:
+//
+//	var ErrNotFound = errors.Define(404, "{{ .Kind }} '{{ .Name }}' not found").
+//		Help("create the {{ .Kind }} first")
+//
+//	return ErrNotFound.With(map[string]any{"Kind": "user", "Name": name})
+func Define(code int, message string) *Error {
+	return newError(message, defaultInit.Load(), 1).Code(code).Freeze()
+}
+
+func (b *Init) Define(code int, message string) *Error {
+	return newError(message, b, 1).initializer(b).Code(code).Freeze()
+}
+
+// With returns an instance of the error with its templates executed,
+// the texts with missing data are left as they are.
+// The data is available for the error templates as .Parameters, it does not replace the data of the templates.
+// The instance matches the error with errors.Is.
+func (e *Error) With(data map[string]any) *Error {
+	result := e.Clone()
+	result.frozen = e.frozen
+
+	init := result.getInit()
+
+	if init.stackDepth > 0 {
+		result.stack = callers(1, init.stackDepth)
+	}
+
+	parameters := maps.Clone(e.parameters)
+	if parameters == nil {
+		parameters = make(map[string]any, len(data))
+	}
+
+	maps.Copy(parameters, data)
+	result.parameters = parameters
+
+	// the texts are executed with the additional template data as well
+	merged := maps.Clone(e.additionalTemplateData)
+	if merged == nil {
+		merged = make(map[string]any, len(parameters))
+	}
+
+	maps.Copy(merged, parameters)
+
+	result.message = executeText(init, result.message, merged)
+
+	for i, note := range result.notes {
+		result.notes[i] = executeText(init, note, merged)
+	}
+
+	for i, help := range result.helps {
+		result.helps[i] = executeText(init, help, merged)
+	}
+
+	return result
+}
+
+// executeText executes the text as a template,
+// it falls back to the original text if it is not a valid template or the data is missing.
+func executeText(init *Init, text string, data map[string]any) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+
+	tmpl, err := template.New("text").Option("missingkey=error").Funcs(plainPalette.funcMap(init.funcMap)).Parse(text)
+	if err != nil {
+		log.Printf("failed to parse error text template: %v", err)
+		return text
+	}
+
+	var result strings.Builder
+
+	if err := tmpl.Execute(&result, data); err != nil {
+		log.Printf("failed to execute error text template: %v", err)
+		return text
+	}
+
+	return result.String()
+}
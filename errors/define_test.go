@@ -0,0 +1,58 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+var errNotFound = Define(404, "{{ .Kind }} '{{ .Name }}' not found").
+	Help("create the {{ .Kind }} first").
+	Note("{{ .Kind }}s are created by the administrators")
+
+func Test_Define(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	user := errNotFound.With(map[string]any{"Kind": "user", "Name": "foo"})
+	group := errNotFound.With(map[string]any{"Kind": "group", "Name": "bar"})
+
+	assert.Equal(t, `error[E0404]: user 'foo' not found
+   = note: users are created by the administrators
+   = help: create the user first`, user.Error())
+	assert.Equal(t, `error[E0404]: group 'bar' not found
+   = note: groups are created by the administrators
+   = help: create the group first`, group.Error())
+
+	// the definition is not modified
+	assert.Equal(t, "{{ .Kind }} '{{ .Name }}' not found", errNotFound.GetMessage())
+
+	assert.True(t, Is(user, errNotFound))
+	assert.True(t, Is(fmt.Errorf("wrapped: %w", group), errNotFound))
+	assert.False(t, Is(New("not found"), errNotFound))
+
+	// the data is available for the templates of the error too
+	init := NewInitializer(WithTemplateDefinition(TemplateDefinitionHelps, `{{ define "helps" }} ({{ .Parameters.Name }}){{ end }}`))
+	definition := init.Define(1, "{{ .Kind }} not found")
+
+	assert.Equal(t, "error[E0001]: user not found (foo)",
+		definition.With(map[string]any{"Kind": "user", "Name": "foo"}).Error())
+	assert.True(t, definition.IsFrozen())
+
+	// the color functions of the texts are not colored, the texts are colored by the renderer
+	assert.Equal(t, "error[E0001]: user not found",
+		Sprint(Define(1, "{{ bold .Kind }} not found").With(map[string]any{"Kind": "user"}), RenderOptions{Color: ColorNever}))
+
+	// the parameters do not replace the data of the templates
+	assert.Equal(t, "error[E0012]: the severity is high",
+		Define(12, "the severity is {{ .Severity }}").With(map[string]any{"Severity": "high", "Code": 1}).Error())
+
+	// the texts with missing data are rendered as they are
+	assert.Equal(t, "error[E0001]: {{ .Kind }} not found", Define(1, "{{ .Kind }} not found").With(nil).Error())
+
+	// invalid templates are rendered as they are
+	assert.Equal(t, "error[E0001]: {{ .Kind", Define(1, "{{ .Kind").With(nil).Error())
+}
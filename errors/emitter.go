@@ -0,0 +1,193 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Emitter collects the diagnostics of a run and renders them to a writer.
+// It is safe for concurrent use.
+type Emitter struct {
+	w             io.Writer
+	deferred      bool
+	renderOptions RenderOptions
+	color         bool
+	width         int
+
+	mu          sync.Mutex
+	seen        map[string]bool
+	diagnostics []*Error
+	rendered    []string
+	errors      int
+	warnings    int
+	finished    bool
+	result      error
+}
+
+type EmitterOption func(*Emitter)
+
+// WithDeferredOutput renders the diagnostics when Finish is called instead of when they are emitted.
+func WithDeferredOutput() EmitterOption {
+	return func(em *Emitter) {
+		em.deferred = true
+	}
+}
+
+// WithRenderOptions changes how the diagnostics are rendered,
+// by default the colors and the wrapping depend on whether the writer is a terminal.
+func WithRenderOptions(opts RenderOptions) EmitterOption {
+	return func(em *Emitter) {
+		em.renderOptions = opts
+	}
+}
+
+func NewEmitter(w io.Writer, opts ...EmitterOption) *Emitter {
+	result := &Emitter{
+		w:    w,
+		seen: make(map[string]bool),
+	}
+
+	for _, opt := range opts {
+		opt(result)
+	}
+
+	result.color = result.renderOptions.colored(w)
+	result.width = result.renderOptions.width(w)
+
+	return result
+}
+
+// Emit collects an error, identical errors are emitted only once.
+// The first *Error in the chain of err is emitted, other errors are emitted as errors with their message.
+// Nil errors, including a nil *Error, are ignored.
+func (em *Emitter) Emit(err error) {
+	if err == nil {
+		return
+	}
+
+	var e *Error
+	if !As(err, &e) {
+		e = New(err.Error())
+	}
+
+	if e == nil {
+		return
+	}
+
+	// the colors do not matter when the errors are compared
+	key := newRenderer(false, false).render(e)
+	rendered := em.render(e)
+
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	if em.seen[key] {
+		return
+	}
+
+	em.seen[key] = true
+	em.diagnostics = append(em.diagnostics, e)
+	em.rendered = append(em.rendered, rendered)
+
+	switch e.severity {
+	case SeverityError, SeverityBug:
+		em.errors++
+	case SeverityWarning:
+		em.warnings++
+	}
+
+	if !em.deferred {
+		em.write(rendered)
+	}
+}
+
+func (em *Emitter) ErrorCount() int {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	return em.errors
+}
+
+func (em *Emitter) WarningCount() int {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	return em.warnings
+}
+
+// Finish renders the deferred diagnostics and the summary of the run.
+// It returns an error that wraps every error level diagnostic or nil if there were only warnings.
+// Calling it again returns the same result without rendering anything.
+func (em *Emitter) Finish() error {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	if em.finished {
+		return em.result
+	}
+
+	em.finished = true
+
+	if em.deferred {
+		for _, rendered := range em.rendered {
+			em.write(rendered)
+		}
+	}
+
+	summary := em.summary()
+	if summary == nil {
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(em.w, em.render(summary))
+
+	if em.errors == 0 {
+		return nil
+	}
+
+	for _, diagnostic := range em.diagnostics {
+		if diagnostic.severity == SeverityError || diagnostic.severity == SeverityBug {
+			summary.Wrap(diagnostic)
+		}
+	}
+
+	em.result = summary
+
+	return summary
+}
+
+func (em *Emitter) write(rendered string) {
+	_, _ = fmt.Fprintf(em.w, "%s\n\n", rendered)
+}
+
+func (em *Emitter) summary() *Error {
+	warnings := ""
+	if em.warnings > 0 {
+		warnings = fmt.Sprintf("%d %s emitted", em.warnings, plural(em.warnings, "warning"))
+	}
+
+	switch {
+	case em.errors > 0 && em.warnings > 0:
+		return Newf("aborting due to %d previous %s; %s", em.errors, plural(em.errors, "error"), warnings)
+	case em.errors > 0:
+		return Newf("aborting due to %d previous %s", em.errors, plural(em.errors, "error"))
+	case em.warnings > 0:
+		return New(warnings).SetSeverity(SeverityWarning)
+	default:
+		return nil
+	}
+}
+
+func plural(count int, word string) string {
+	if count == 1 {
+		return word
+	}
+
+	return word + "s"
+}
+
+// render renders the diagnostic with a new renderer because the errors can be emitted concurrently.
+func (em *Emitter) render(e *Error) string {
+	return newRenderer(em.color, false).withWidth(em.width).withFormat(em.renderOptions.Format).renderDocument(e)
+}
@@ -0,0 +1,124 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Emitter(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	for _, tt := range []struct {
+		name         string
+		opts         []EmitterOption
+		emit         []error
+		beforeFinish string
+		expected     string
+		err          string
+	}{
+		{
+			name:     "nothing emitted",
+			expected: "",
+		},
+		{
+			name:         "errors and warnings are rendered as they arrive",
+			emit:         []error{New("first").Code(1), io.EOF, New("warning").SetSeverity(SeverityWarning), New("note").SetSeverity(SeverityNote)},
+			beforeFinish: "error[E0001]: first\n\nerror: EOF\n\nwarning: warning\n\nnote: note\n\n",
+			expected: "error[E0001]: first\n\nerror: EOF\n\nwarning: warning\n\nnote: note\n\n" +
+				"error: aborting due to 2 previous errors; 1 warning emitted\n",
+			err: "error: aborting due to 2 previous errors; 1 warning emitted\n\nerror[E0001]: first\n\nerror: EOF",
+		},
+		{
+			name:         "deferred output",
+			opts:         []EmitterOption{WithDeferredOutput()},
+			emit:         []error{New("first"), New("bug").SetSeverity(SeverityBug)},
+			beforeFinish: "",
+			expected: "error: first\n\nerror: internal compiler error: bug\n\n" +
+				"error: aborting due to 2 previous errors\n",
+			err: "error: aborting due to 2 previous errors\n\nerror: first\n\nerror: internal compiler error: bug",
+		},
+		{
+			name:         "identical errors are emitted once",
+			emit:         []error{New("first"), New("first"), nil, (*Error)(nil), New("first").Help("different")},
+			beforeFinish: "error: first\n\nerror: first\n   = help: different\n\n",
+			expected: "error: first\n\nerror: first\n   = help: different\n\n" +
+				"error: aborting due to 2 previous errors\n",
+			err: "error: aborting due to 2 previous errors\n\nerror: first\n\nerror: first\n   = help: different",
+		},
+		{
+			name:         "only warnings",
+			emit:         []error{New("first").SetSeverity(SeverityWarning), New("second").SetSeverity(SeverityWarning)},
+			beforeFinish: "warning: first\n\nwarning: second\n\n",
+			expected:     "warning: first\n\nwarning: second\n\nwarning: 2 warnings emitted\n",
+		},
+		{
+			name:         "wrapped diagnostics keep their severity",
+			emit:         []error{fmt.Errorf("context: %w", New("first").SetSeverity(SeverityWarning))},
+			beforeFinish: "warning: first\n\n",
+			expected:     "warning: first\n\nwarning: 1 warning emitted\n",
+		},
+		{
+			name:         "one error",
+			emit:         []error{New("first")},
+			beforeFinish: "error: first\n\n",
+			expected:     "error: first\n\nerror: aborting due to 1 previous error\n",
+			err:          "error: aborting due to 1 previous error\n\nerror: first",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			emitter := NewEmitter(&out, tt.opts...)
+
+			for _, err := range tt.emit {
+				emitter.Emit(err)
+			}
+
+			assert.Equal(t, tt.beforeFinish, out.String())
+
+			err := emitter.Finish()
+			assert.Equal(t, tt.expected, out.String())
+
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Equal(t, tt.err, err.Error())
+			}
+
+			assert.Equal(t, err, emitter.Finish(), "finishing again returns the same result")
+			assert.Equal(t, tt.expected, out.String(), "finishing again renders nothing")
+		})
+	}
+}
+
+func Test_Emitter_Concurrent(t *testing.T) {
+	emitter := NewEmitter(io.Discard)
+
+	var wg sync.WaitGroup
+
+	for i := range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			emitter.Emit(Newf("error %d", i))
+			emitter.Emit(New("warning").SetSeverity(SeverityWarning))
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 10, emitter.ErrorCount())
+	assert.Equal(t, 1, emitter.WarningCount())
+}
@@ -1,42 +1,63 @@
 package errors
 
 import (
-	"cmp"
 	"errors"
 	"fmt"
-	"log"
-	"maps"
+	"io"
 	"slices"
 	"strings"
 
-	expmaps "golang.org/x/exp/maps"
-
-	"github.com/agext/levenshtein"
+	"github.com/fatih/color"
 )
 
 type Error struct {
-	message string
-	cause   error
-	code    string
-	helps   []string
-	notes   []string
+	message  string
+	cause    error
+	code     string
+	severity Severity
+	helps    []string
+	notes    []string
+
+	snippets    []Snippet
+	labels      []Label
+	suggestions []Suggestion
 
 	additionalTemplateData map[string]any
+	// parameters is the data of an instance of a definition, see With
+	parameters map[string]any
 
 	wrapped []error
 
+	stack []Frame
+
 	init *Init
+
+	// frozen errors are copied by the builder methods, origin is the error the copy was made from
+	frozen bool
+	origin *Error
 }
 
 func New(message string) *Error {
-	return &Error{
+	return newError(message, defaultInit.Load(), 1)
+}
+
+func Newf(format string, args ...any) *Error {
+	return newError(fmt.Sprintf(format, args...), defaultInit.Load(), 1)
+}
+
+// newError creates an error and captures the stack trace if it is enabled by the initializer,
+// skip is the number of frames between the caller and newError.
+func newError(message string, init *Init, skip int) *Error {
+	result := &Error{
 		message: message,
 		wrapped: make([]error, 0),
 	}
-}
 
-func Newf(format string, args ...any) *Error {
-	return New(fmt.Sprintf(format, args...))
+	if init.stackDepth > 0 {
+		result.stack = callers(skip+1, init.stackDepth)
+	}
+
+	return result
 }
 
 func Extend(err error) *Error {
@@ -45,33 +66,60 @@ func Extend(err error) *Error {
 		return e
 	}
 
-	return New(err.Error())
+	return newError(err.Error(), defaultInit.Load(), 1)
 }
 
 func ExtendWithMessage(err error, message string) *Error {
 	var e *Error
 	if errors.As(err, &e) {
 		// override the original message
+		e = e.mutable()
 		e.message = message
 		return e
 	}
 
-	return New(err.Error())
+	return newError(err.Error(), defaultInit.Load(), 1)
+}
+
+// Is is a shorthand for the standard library's errors.Is
+// so that the package can be used without importing both.
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As is a shorthand for the standard library's errors.As.
+func As(err error, target any) bool {
+	return errors.As(err, target)
 }
 
 func (e *Error) initializer(b *Init) *Error {
+	e = e.mutable()
 	e.init = b
 
 	return e
 }
 
+func (e *Error) getInit() *Init {
+	if e.init != nil {
+		return e.init
+	}
+
+	return defaultInit.Load()
+}
+
 func (e *Error) Wrap(err error) *Error {
+	if err == nil {
+		return e
+	}
+
+	e = e.mutable()
 	e.wrapped = append(e.wrapped, err)
 
 	return e
 }
 
 func (e *Error) Cause(err error) *Error {
+	e = e.mutable()
 	e.cause = err
 
 	return e
@@ -81,21 +129,12 @@ func (e *Error) Causef(format string, args ...any) *Error {
 	return e.Cause(fmt.Errorf(format, args...))
 }
 
-func (e *Error) Code(code int) *Error {
-	if code < 0 || code > 9999 {
-		panic(fmt.Sprintf("number out of range: %d", code))
-	}
-
-	e.code = fmt.Sprintf("E%04d", code)
-
-	return e
-}
-
 func (e *Error) Help(help string) *Error {
 	if help == "" {
 		return e
 	}
 
+	e = e.mutable()
 	e.helps = append(e.helps, help)
 
 	return e
@@ -122,6 +161,7 @@ func (e *Error) Note(note string) *Error {
 		return e
 	}
 
+	e = e.mutable()
 	e.notes = append(e.notes, note)
 
 	return e
@@ -132,6 +172,7 @@ func (e *Error) Notef(format string, args ...any) *Error {
 }
 
 func (e *Error) AdditionalTemplateData(data map[string]any) *Error {
+	e = e.mutable()
 	e.additionalTemplateData = data
 
 	return e
@@ -141,92 +182,262 @@ func (e *Error) GetMessage() string {
 	return e.message
 }
 
+func (e *Error) GetCause() error {
+	return e.cause
+}
+
+func (e *Error) GetNotes() []string {
+	return e.notes
+}
+
+func (e *Error) GetHelps() []string {
+	return e.helps
+}
+
+// Summary returns the message with the cause on a single line for the formats that list the errors one by one.
+// Causes that are *Error are left out, they are listed on their own, see Walk.
+func (e *Error) Summary() string {
+	var cause *Error
+	if e.cause == nil || As(e.cause, &cause) {
+		return e.message
+	}
+
+	return e.message + ": " + e.cause.Error()
+}
+
 func (e *Error) Error() string {
-	var result strings.Builder
+	return newRenderer(!color.NoColor, false).render(e)
+}
+
+// Format implements fmt.Formatter:
+//   - %s and %v render a compact single line: "message: cause: wrapped"
+//   - %+v renders the full diagnostic with the stack traces
+//   - %#v dumps the structure of the error
+//   - %q renders the compact form quoted
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		switch {
+		case s.Flag('+'):
+			_, _ = io.WriteString(s, newRenderer(!color.NoColor, true).render(e))
+		case s.Flag('#'):
+			_, _ = io.WriteString(s, e.GoString())
+		default:
+			_, _ = io.WriteString(s, e.compact())
+		}
+	case 's':
+		_, _ = io.WriteString(s, e.compact())
+	case 'q':
+		_, _ = fmt.Fprintf(s, "%q", e.compact())
+	default:
+		_, _ = fmt.Fprintf(s, "%%!%c(*errors.Error=%s)", verb, e.compact())
+	}
+}
+
+// compact joins the message, the cause and the wrapped errors in a single line.
+func (e *Error) compact() string {
+	return e.compactPath(nil)
+}
+
+// compactPath renders the compact form, path is the chain of the errors being rendered.
+func (e *Error) compactPath(path []*Error) string {
+	if slices.Contains(path, e) {
+		return cycleMarker
+	}
+
+	path = append(path, e)
+	parts := []string{e.message}
+
+	if e.cause != nil {
+		parts = append(parts, compact(e.cause, path))
+	}
+
+	for _, err := range e.wrapped {
+		parts = append(parts, compact(err, path))
+	}
+
+	return strings.Join(parts, ": ")
+}
+
+func compact(err error, path []*Error) string {
+	if e, ok := err.(*Error); ok {
+		return e.compactPath(slices.Clip(path))
+	}
+
+	var lines []string
+
+	for _, line := range strings.Split(err.Error(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, " ")
+}
+
+// GoString dumps the fields of the error that are set.
+func (e *Error) GoString() string {
+	return e.goStringPath(nil)
+}
+
+// goStringPath dumps the fields, path is the chain of the errors being dumped.
+func (e *Error) goStringPath(path []*Error) string {
+	if slices.Contains(path, e) {
+		return cycleMarker
+	}
+
+	path = append(path, e)
+
+	var sb strings.Builder
+
+	sb.WriteString("&errors.Error{")
+	_, _ = fmt.Fprintf(&sb, "message:%q", e.message)
+
+	field := func(name string, value any) {
+		_, _ = fmt.Fprintf(&sb, ", %s:%#v", name, value)
+	}
+
+	if e.code != "" {
+		field("code", e.code)
+	}
+
+	if e.severity != SeverityError {
+		field("severity", e.severity)
+	}
+
+	if e.cause != nil {
+		_, _ = fmt.Fprintf(&sb, ", cause:%s", goString(e.cause, path))
+	}
+
+	if len(e.notes) > 0 {
+		field("notes", e.notes)
+	}
 
-	data := map[string]any{
-		dataMessage: e.message,
-		dataCause:   e.cause,
-		dataWrapped: e.wrapped,
-		dataCode:    e.code,
-		dataNotes:   e.notes,
-		dataHelps:   e.helps,
+	if len(e.helps) > 0 {
+		field("helps", e.helps)
+	}
+
+	if len(e.snippets) > 0 {
+		field("snippets", e.snippets)
+	}
+
+	if len(e.labels) > 0 {
+		field("labels", e.labels)
+	}
+
+	if len(e.suggestions) > 0 {
+		field("suggestions", e.suggestions)
 	}
 
 	if len(e.additionalTemplateData) > 0 {
-		maps.Copy(data, e.additionalTemplateData)
+		field("additionalTemplateData", e.additionalTemplateData)
 	}
 
-	init := defaultInit
-	if e.init != nil {
-		init = e.init
+	if len(e.parameters) > 0 {
+		field("parameters", e.parameters)
 	}
 
-	if err := init.template.Execute(&result, data); err != nil {
-		log.Printf("failed to execute error template: %v", err)
-		// fall back to just the error message
-		result.WriteString(e.message)
+	if len(e.wrapped) > 0 {
+		wrapped := make([]string, 0, len(e.wrapped))
+		for _, err := range e.wrapped {
+			wrapped = append(wrapped, goString(err, path))
+		}
+
+		_, _ = fmt.Fprintf(&sb, ", wrapped:[]error{%s}", strings.Join(wrapped, ", "))
+	}
+
+	if len(e.stack) > 0 {
+		field("stack", e.stack)
+	}
+
+	sb.WriteString("}")
+
+	return sb.String()
+}
+
+func goString(err error, path []*Error) string {
+	if e, ok := err.(*Error); ok && e != nil {
+		return e.goStringPath(slices.Clip(path))
 	}
 
-	return result.String()
+	return fmt.Sprintf("%#v", err)
 }
 
 func (e *Error) WrappedErrors() []error {
 	return e.wrapped
 }
 
-// SuggestValue provides suggestions for the input based on the available values.
-// useful when the error occurs because of a wrong input value.
-// if the input is an emtpy string it will suggest all available values.
-func (e *Error) SuggestValue(input string, available []string) *Error {
-	if input == "" {
-		e.suggestValuesHelp(available, true)
-		return e
+// Unwrap returns the cause and the wrapped errors
+// so that errors.Is and errors.As can traverse the whole diagnostic tree.
+func (e *Error) Unwrap() []error {
+	result := make([]error, 0, len(e.wrapped)+1)
+
+	if e.cause != nil {
+		result = append(result, e.cause)
 	}
 
-	distances := make(map[string]int)
+	for _, err := range e.wrapped {
+		if err != nil {
+			result = append(result, err)
+		}
+	}
+
+	return result
+}
 
-	for _, item := range available {
-		distances[item] = levenshtein.Distance(input, item, nil)
+// Walk calls fn for every *Error in the tree of err in depth-first order
+// until fn returns false. The tree is traversed by the Unwrap methods of the errors,
+// errors that wrap themselves are visited only once.
+func Walk(err error, fn func(e *Error) bool) {
+	walk(err, fn, nil)
+}
+
+func walk(err error, fn func(e *Error) bool, path []*Error) bool {
+	if err == nil {
+		return true
 	}
 
-	// dist = 0           >> the input is the same as the item
-	// dist >= len(input) >> the input does not contain the item
-	maps.DeleteFunc(distances, func(item string, dist int) bool {
-		return dist >= len(input)
-	})
+	if e, ok := err.(*Error); ok {
+		if slices.Contains(path, e) {
+			return true
+		}
 
-	if len(distances) == 0 {
-		slices.Sort(available)
-		e.suggestValuesHelp(available, true)
-	} else {
-		suggested := expmaps.Keys(distances)
+		if !fn(e) {
+			return false
+		}
 
-		slices.SortFunc(suggested, func(a, b string) int {
-			return cmp.Compare(distances[a], distances[b])
-		})
+		path = append(slices.Clip(path), e)
+	}
 
-		e.suggestValuesHelp(suggested, false)
+	switch x := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, err := range x.Unwrap() {
+			if !walk(err, fn, path) {
+				return false
+			}
+		}
+	case interface{ Unwrap() error }:
+		return walk(x.Unwrap(), fn, path)
 	}
 
-	return e
+	return true
 }
 
-func (e *Error) suggestValuesHelp(suggestions []string, all bool) {
-	if len(suggestions) == 1 {
-		e.helps = append(e.helps, fmt.Sprintf("did you mean: '%s'?", suggestions[0]))
-		return
+// Is reports whether the target is an *Error with the same error code
+// or the error is a copy of the target.
+// Errors without a code only match themselves and their copies.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
 	}
 
-	sb := strings.Builder{}
-
-	if all {
-		sb.WriteString("available values:\n- ")
-	} else {
-		sb.WriteString("did you mean any of these?\n- ")
+	// copies of frozen errors match the errors they were copied from
+	for origin := e.origin; origin != nil; origin = origin.origin {
+		if origin == t {
+			return true
+		}
 	}
 
-	sb.WriteString(strings.Join(suggestions, "\n- "))
-
-	e.helps = append(e.helps, sb.String())
+	return e.code != "" && e.code == t.code
 }
@@ -2,10 +2,16 @@ package errors
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/fatih/color"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_Basic_Usage(t *testing.T) {
@@ -244,12 +250,335 @@ func Test_Reset(t *testing.T) {
 	color.NoColor = true
 	defer func() { color.NoColor = original }()
 
-	originalFuncSize := len(funcMap)
-
 	AdditionalTemplateFunc("custom", func() string { return "" })
+	assert.Contains(t, defaultInit.Load().funcMap, "custom")
 
 	Reset()
 
-	// the original funcMap stays the same
-	assert.Equal(t, len(funcMap), originalFuncSize)
+	// the functions of the options are dropped
+	assert.NotContains(t, defaultInit.Load().funcMap, "custom")
+}
+
+func Test_Unwrap(t *testing.T) {
+	err := New("test").
+		Cause(io.EOF).
+		Wrap(New("wrapped").Cause(io.ErrUnexpectedEOF))
+
+	assert.True(t, errors.Is(err, io.EOF))
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	assert.False(t, errors.Is(err, io.ErrClosedPipe))
+
+	var pathErr *fs.PathError
+	assert.False(t, errors.As(err, &pathErr))
+
+	err = New("test").Wrap(fmt.Errorf("wrapped: %w", &fs.PathError{Op: "open", Path: "x", Err: fs.ErrNotExist}))
+	assert.True(t, errors.As(err, &pathErr))
+	assert.Equal(t, "x", pathErr.Path)
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func Test_Summary(t *testing.T) {
+	assert.Equal(t, "test", New("test").Summary())
+	assert.Equal(t, "test: EOF", New("test").Cause(io.EOF).Summary())
+	assert.Equal(t, "test: wrapped: EOF", New("test").Cause(fmt.Errorf("wrapped: %w", io.EOF)).Summary())
+
+	// the causes that are *Error are listed on their own
+	assert.Equal(t, "test", New("test").Cause(New("cause")).Summary())
+	assert.Equal(t, "test", New("test").Cause(fmt.Errorf("wrapped: %w", New("cause"))).Summary())
+}
+
+func Test_Is(t *testing.T) {
+	notFound := New("not found").Code(404)
+
+	for _, tt := range []struct {
+		name     string
+		err      error
+		target   error
+		expected bool
+	}{
+		{
+			name:     "same instance",
+			err:      notFound,
+			target:   notFound,
+			expected: true,
+		},
+		{
+			name:     "same code",
+			err:      New("user not found").Code(404),
+			target:   notFound,
+			expected: true,
+		},
+		{
+			name:     "different code",
+			err:      New("bad request").Code(400),
+			target:   notFound,
+			expected: false,
+		},
+		{
+			name:     "no code",
+			err:      New("not found"),
+			target:   New("not found"),
+			expected: false,
+		},
+		{
+			name:     "code in the wrapped errors",
+			err:      New("test").Wrap(New("user not found").Code(404)),
+			target:   notFound,
+			expected: true,
+		},
+		{
+			name:     "code in the cause",
+			err:      New("test").Cause(fmt.Errorf("wrapped: %w", New("user not found").Code(404))),
+			target:   notFound,
+			expected: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Is(tt.err, tt.target))
+		})
+	}
+
+	var e *Error
+	assert.True(t, As(New("test").Cause(io.EOF), &e))
+	assert.Equal(t, "test", e.GetMessage())
+}
+
+func Test_StackTrace(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	assert.Empty(t, New("disabled by default").StackTrace())
+
+	SetStackTraceDepth(2)
+	defer Reset()
+
+	const function = "github.com/bsido/go-errors/errors.Test_StackTrace"
+
+	for _, err := range []*Error{
+		New("test"),
+		Newf("test %d", 1),
+		Extend(io.EOF),
+		NewInitializer(WithStackTrace(2)).NewError("test"),
+		NewInitializer(WithStackTrace(2)).NewErrorf("test %d", 1),
+	} {
+		stack := err.StackTrace()
+
+		require.Len(t, stack, 2)
+		assert.Equal(t, function, stack[0].Function)
+		assert.True(t, strings.HasSuffix(stack[0].File, "error_test.go"))
+		assert.Positive(t, stack[0].Line)
+	}
+
+	assert.Empty(t, callers(1000, 2), "no frames when the stack is not deep enough")
+
+	err := New("test").Help("help")
+
+	// the stack trace is rendered only by %+v
+	assert.Equal(t, "error: test\n   = help: help", err.Error())
+	assert.Equal(t, "test", fmt.Sprintf("%v", err))
+	assert.Regexp(t, `^error: test
+   = help: help
+   = stack:
+           github.com/bsido/go-errors/errors.Test_StackTrace
+               .*/errors/error_test.go:\d+
+           testing.tRunner
+               .*/testing.go:\d+$`, fmt.Sprintf("%+v", err))
+}
+
+func Test_Format(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	err := New("test").
+		Code(1).
+		Cause(errors.New("cause\n  second line")).
+		Help("help").
+		Wrap(New("wrapped").Cause(New("wrapped cause"))).
+		Wrap(New("last"))
+
+	for _, tt := range []struct {
+		format   string
+		expected string
+	}{
+		{
+			format:   "%s",
+			expected: "test: cause second line: wrapped: wrapped cause: last",
+		},
+		{
+			format:   "%v",
+			expected: "test: cause second line: wrapped: wrapped cause: last",
+		},
+		{
+			format:   "%q",
+			expected: `"test: cause second line: wrapped: wrapped cause: last"`,
+		},
+		{
+			format:   "%+v",
+			expected: err.Error(),
+		},
+		{
+			format: "%#v",
+			expected: `&errors.Error{message:"test", code:"E0001", cause:&errors.errorString{s:"cause\n  second line"}, ` +
+				`helps:[]string{"help"}, wrapped:[]error{&errors.Error{message:"wrapped", cause:&errors.Error{message:"wrapped cause"}}, ` +
+				`&errors.Error{message:"last"}}}`,
+		},
+		{
+			format:   "%d",
+			expected: "%!d(*errors.Error=test: cause second line: wrapped: wrapped cause: last)",
+		},
+	} {
+		t.Run(tt.format, func(t *testing.T) {
+			assert.Equal(t, tt.expected, fmt.Sprintf(tt.format, err))
+		})
+	}
+}
+
+func Test_Severity(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	for _, tt := range []struct {
+		severity Severity
+		expected string
+	}{
+		{
+			severity: SeverityError,
+			expected: "error[E0001]: test",
+		},
+		{
+			severity: SeverityWarning,
+			expected: "warning[E0001]: test",
+		},
+		{
+			severity: SeverityNote,
+			expected: "note[E0001]: test",
+		},
+		{
+			severity: SeverityInfo,
+			expected: "info[E0001]: test",
+		},
+		{
+			severity: SeverityBug,
+			expected: "error: internal compiler error: test",
+		},
+	} {
+		t.Run(tt.severity.String(), func(t *testing.T) {
+			err := New("test").Code(1).SetSeverity(tt.severity)
+
+			assert.Equal(t, tt.severity, err.Severity())
+			assert.Equal(t, tt.expected, err.Error())
+		})
+	}
+
+	assert.Equal(t, SeverityError, New("test").Severity())
+
+	err := New("test").
+		Cause(fmt.Errorf("cause: %w", New("note").SetSeverity(SeverityNote))).
+		Wrap(New("warning").SetSeverity(SeverityWarning))
+
+	assert.True(t, IsSeverity(err, SeverityError))
+	assert.True(t, IsSeverity(err, SeverityWarning))
+	assert.True(t, IsSeverity(err, SeverityNote))
+	assert.False(t, IsSeverity(err, SeverityBug))
+	assert.False(t, IsSeverity(io.EOF, SeverityError))
+}
+
+func Test_Code(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	for _, tt := range []struct {
+		name     string
+		err      *Error
+		expected string
+	}{
+		{
+			name:     "default format",
+			err:      New("test").Code(42),
+			expected: "E0042",
+		},
+		{
+			name:     "prefix without padding",
+			err:      NewInitializer(WithCodeFormat("AUTH-", 0)).NewError("test").Code(123),
+			expected: "AUTH-123",
+		},
+		{
+			name:     "prefix with padding",
+			err:      NewInitializer(WithCodeFormat("DB", 4)).NewError("test").Code(42),
+			expected: "DB0042",
+		},
+		{
+			name:     "string code",
+			err:      New("test").CodeString("AUTH-123"),
+			expected: "AUTH-123",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.err.GetCode())
+			assert.Equal(t, "error["+tt.expected+"]: test", tt.err.Error())
+		})
+	}
+
+	assert.PanicsWithValue(t, "number out of range: 10000", func() { New("test").Code(10000) })
+	assert.PanicsWithValue(t, "number out of range: -1", func() { New("test").Code(-1) })
+	assert.PanicsWithValue(t, "number out of range: 100", func() {
+		NewInitializer(WithCodeFormat("X", 2)).NewError("test").Code(100)
+	})
+	assert.NotPanics(t, func() { NewInitializer(WithCodeFormat("X", 0)).NewError("test").Code(100000) })
+	assert.PanicsWithValue(t, "negative code width: -1", func() { WithCodeFormat("X", -1) })
+	assert.PanicsWithValue(t, "negative code width: -2", func() { SetCodeFormat("X", -2) })
+
+	SetCodeFormat("APP-", 3)
+	defer Reset()
+
+	assert.Equal(t, "APP-007", New("test").Code(7).GetCode())
+}
+
+// Test_Concurrent_Configuration should be run with -race
+func Test_Concurrent_Configuration(t *testing.T) {
+	defer Reset()
+
+	sentinel := New("sentinel").Code(1).Help("help")
+
+	var wg sync.WaitGroup
+
+	for i := range 8 {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			for range 50 {
+				_ = New("test").Code(2).Note("note").Cause(io.EOF).Wrap(sentinel).Error()
+				_ = fmt.Sprintf("%+v", sentinel)
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			for j := range 50 {
+				switch (i + j) % 6 {
+				case 0:
+					SetCauseTemplate(causeTemplate)
+				case 1:
+					SetMessagePrefixTemplate(messagePrefixTemplate)
+				case 2:
+					AdditionalTemplateFunc(fmt.Sprintf("custom%d", j), func() string { return "" })
+				case 3:
+					SetCodeFormat("E", 4)
+				case 4:
+					SetStackTraceDepth(j % 3)
+				default:
+					Reset()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
 }
@@ -0,0 +1,171 @@
+package fix
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around the changes in the unified diff.
+const diffContext = 3
+
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff returns the changes between the contents of a file in the unified format.
+func unifiedDiff(name string, before, after []byte) string {
+	ops := diffLines(splitLines(string(before)), splitLines(string(after)))
+
+	var changes []int
+
+	for i, op := range ops {
+		if op.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	_, _ = fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", name, name)
+
+	for i := 0; i < len(changes); {
+		// the changes whose contexts overlap are in the same hunk
+		last := i
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*diffContext {
+			last++
+		}
+
+		start := max(changes[i]-diffContext, 0)
+		end := min(changes[last]+diffContext+1, len(ops))
+
+		writeHunk(&sb, ops, start, end)
+
+		i = last + 1
+	}
+
+	return sb.String()
+}
+
+func writeHunk(sb *strings.Builder, ops []diffOp, start, end int) {
+	beforeStart, afterStart := 1, 1
+
+	for _, op := range ops[:start] {
+		if op.kind != '+' {
+			beforeStart++
+		}
+
+		if op.kind != '-' {
+			afterStart++
+		}
+	}
+
+	beforeCount, afterCount := 0, 0
+
+	for _, op := range ops[start:end] {
+		if op.kind != '+' {
+			beforeCount++
+		}
+
+		if op.kind != '-' {
+			afterCount++
+		}
+	}
+
+	// an empty range starts at the line before it
+	if beforeCount == 0 {
+		beforeStart--
+	}
+
+	if afterCount == 0 {
+		afterStart--
+	}
+
+	_, _ = fmt.Fprintf(sb, "@@ -%d,%d +%d,%d @@\n", beforeStart, beforeCount, afterStart, afterCount)
+
+	for _, op := range ops[start:end] {
+		sb.WriteByte(op.kind)
+		sb.WriteString(op.line)
+
+		if !strings.HasSuffix(op.line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// splitLines splits the text after the line breaks.
+func splitLines(text string) []string {
+	result := strings.SplitAfter(text, "\n")
+	if result[len(result)-1] == "" {
+		result = result[:len(result)-1]
+	}
+
+	return result
+}
+
+// diffLines returns the operations that turn the lines before into the lines after.
+// The common prefix and suffix are trimmed before the longest common subsequence is computed,
+// so the suggestions that change a few lines of a large file are cheap to compare.
+func diffLines(before, after []string) []diffOp {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	result := make([]diffOp, 0, len(before)+len(after))
+
+	for _, line := range before[:prefix] {
+		result = append(result, diffOp{kind: ' ', line: line})
+	}
+
+	a, b := before[prefix:len(before)-suffix], after[prefix:len(after)-suffix]
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			result = append(result, diffOp{kind: ' ', line: a[i]})
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] > lcs[i+1][j]):
+			result = append(result, diffOp{kind: '+', line: b[j]})
+			j++
+		default:
+			result = append(result, diffOp{kind: '-', line: a[i]})
+			i++
+		}
+	}
+
+	for _, line := range before[len(before)-suffix:] {
+		result = append(result, diffOp{kind: ' ', line: line})
+	}
+
+	return result
+}
@@ -0,0 +1,304 @@
+// Package fix applies the suggestions of the diagnostics to the source code, similarly to cargo fix.
+package fix
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bsido/go-errors/errors"
+)
+
+// Skipped is a suggestion that was not applied.
+type Skipped struct {
+	Suggestion errors.Suggestion
+	Reason     string
+}
+
+// Result is the outcome of applying the suggestions.
+type Result struct {
+	// Files are the fixed contents of the changed files by their names.
+	Files   map[string][]byte
+	Applied []errors.Suggestion
+	Skipped []Skipped
+
+	original map[string][]byte
+}
+
+type Option func(*options)
+
+type options struct {
+	applicability []errors.Applicability
+	dryRun        bool
+}
+
+// WithApplicability applies the suggestions with the given applicability,
+// only the machine-applicable suggestions are applied by default.
+func WithApplicability(applicability ...errors.Applicability) Option {
+	return func(opts *options) {
+		opts.applicability = applicability
+	}
+}
+
+// WithDryRun does not write the fixed files, the changes can be previewed with Result.Diff.
+func WithDryRun() Option {
+	return func(opts *options) {
+		opts.dryRun = true
+	}
+}
+
+func newOptions(opts []Option) *options {
+	result := &options{
+		applicability: []errors.Applicability{errors.ApplicabilityMachineApplicable},
+	}
+
+	for _, opt := range opts {
+		opt(result)
+	}
+
+	return result
+}
+
+// edit is a suggestion located in the content of its file by byte offsets.
+type edit struct {
+	errors.Suggestion
+	start int
+	end   int
+}
+
+// Apply applies the suggestions in the trees of the diagnostics to the contents of the files,
+// the files are keyed by the file names of the suggestions. The contents are not modified.
+// A suggestion is skipped if its file is not available, its original text is not in the file
+// or it overlaps with a suggestion before it.
+func Apply(files map[string][]byte, diagnostics []error, opts ...Option) *Result {
+	options := newOptions(opts)
+
+	result := &Result{
+		Files:    make(map[string][]byte),
+		original: make(map[string][]byte),
+	}
+
+	var names []string
+
+	edits := make(map[string][]edit)
+
+	for _, suggestion := range suggestions(diagnostics) {
+		if !slices.Contains(options.applicability, suggestion.Applicability) {
+			result.skip(suggestion, "the suggestion is %s", suggestion.Applicability)
+			continue
+		}
+
+		content, ok := files[suggestion.File]
+		if !ok {
+			result.skip(suggestion, "the file is not available")
+			continue
+		}
+
+		start, end, ok := locate(content, suggestion)
+		if !ok {
+			result.skip(suggestion, "the location is outside of the file")
+			continue
+		}
+
+		if strings.ReplaceAll(string(content[start:end]), "\r\n", "\n") != suggestion.Original {
+			result.skip(suggestion, "the original text is not in the file")
+			continue
+		}
+
+		if _, ok := edits[suggestion.File]; !ok {
+			names = append(names, suggestion.File)
+		}
+
+		edits[suggestion.File] = append(edits[suggestion.File], edit{
+			Suggestion: suggestion,
+			start:      start,
+			end:        end,
+		})
+	}
+
+	for _, name := range names {
+		result.apply(name, files[name], edits[name])
+	}
+
+	return result
+}
+
+// ApplyFiles reads the files of the suggestions, applies the suggestions to them like Apply
+// and writes the changed files unless WithDryRun is set. Files that do not exist are skipped.
+func ApplyFiles(diagnostics []error, opts ...Option) (*Result, error) {
+	files := make(map[string][]byte)
+
+	for _, suggestion := range suggestions(diagnostics) {
+		if _, ok := files[suggestion.File]; ok {
+			continue
+		}
+
+		content, err := os.ReadFile(suggestion.File)
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, errors.Newf("failed to read %s", suggestion.File).Cause(err)
+		}
+
+		files[suggestion.File] = content
+	}
+
+	result := Apply(files, diagnostics, opts...)
+
+	if newOptions(opts).dryRun {
+		return result, nil
+	}
+
+	for name, content := range result.Files {
+		info, err := os.Stat(name)
+		if err != nil {
+			return result, errors.Newf("failed to write %s", name).Cause(err)
+		}
+
+		if err := os.WriteFile(name, content, info.Mode().Perm()); err != nil {
+			return result, errors.Newf("failed to write %s", name).Cause(err)
+		}
+	}
+
+	return result, nil
+}
+
+// Diff returns the changes of the files as a unified diff.
+func (r *Result) Diff() string {
+	names := make([]string, 0, len(r.Files))
+	for name := range r.Files {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	var sb strings.Builder
+
+	for _, name := range names {
+		sb.WriteString(unifiedDiff(name, r.original[name], r.Files[name]))
+	}
+
+	return sb.String()
+}
+
+func (r *Result) skip(suggestion errors.Suggestion, format string, args ...any) {
+	r.Skipped = append(r.Skipped, Skipped{
+		Suggestion: suggestion,
+		Reason:     fmt.Sprintf(format, args...),
+	})
+}
+
+// apply applies the edits of a file in the order of their locations,
+// an edit that overlaps with the previous one is skipped.
+// Insertions at the same location overlap because their order is ambiguous.
+func (r *Result) apply(name string, content []byte, edits []edit) {
+	slices.SortStableFunc(edits, func(a, b edit) int {
+		return a.start - b.start
+	})
+
+	var (
+		fixed    bytes.Buffer
+		position int
+		previous *edit
+	)
+
+	for i := range edits {
+		current := &edits[i]
+
+		if previous != nil && (current.start < previous.end || current.start == previous.start) {
+			r.skip(current.Suggestion, "it overlaps with the suggestion at %s:%d:%d",
+				previous.File, previous.Line, previous.Column)
+
+			continue
+		}
+
+		fixed.Write(content[position:current.start])
+		fixed.WriteString(current.Replacement)
+		position = current.end
+		previous = current
+
+		r.Applied = append(r.Applied, current.Suggestion)
+	}
+
+	fixed.Write(content[position:])
+
+	if !bytes.Equal(content, fixed.Bytes()) {
+		r.original[name] = content
+		r.Files[name] = fixed.Bytes()
+	}
+}
+
+// suggestions collects the suggestions of the diagnostics and their wrapped errors,
+// the same suggestion is collected only once.
+func suggestions(diagnostics []error) []errors.Suggestion {
+	var result []errors.Suggestion
+
+	seen := make(map[errors.Suggestion]bool)
+
+	for _, diagnostic := range diagnostics {
+		errors.Walk(diagnostic, func(e *errors.Error) bool {
+			for _, suggestion := range e.Suggestions() {
+				if !seen[suggestion] {
+					seen[suggestion] = true
+					result = append(result, suggestion)
+				}
+			}
+
+			return true
+		})
+	}
+
+	return result
+}
+
+// locate converts the line, the column and the span of the suggestion to byte offsets.
+// The columns and the span are counted in characters, a line break counts as one character.
+// Suggestions with a negative span are not located, they are helps without the source code.
+func locate(content []byte, suggestion errors.Suggestion) (int, int, bool) {
+	if suggestion.Line < 1 || suggestion.Column < 1 || suggestion.Span < 0 {
+		return 0, 0, false
+	}
+
+	offset := 0
+
+	for line := 1; line < suggestion.Line; line++ {
+		i := bytes.IndexByte(content[offset:], '\n')
+		if i < 0 {
+			return 0, 0, false
+		}
+
+		offset += i + 1
+	}
+
+	for column := 1; column < suggestion.Column; column++ {
+		r, size := utf8.DecodeRune(content[offset:])
+		if size == 0 || r == '\n' || bytes.HasPrefix(content[offset:], []byte("\r\n")) {
+			return 0, 0, false
+		}
+
+		offset += size
+	}
+
+	start := offset
+
+	for range suggestion.Span {
+		if offset >= len(content) {
+			return 0, 0, false
+		}
+
+		if bytes.HasPrefix(content[offset:], []byte("\r\n")) {
+			offset += 2
+			continue
+		}
+
+		_, size := utf8.DecodeRune(content[offset:])
+		offset += size
+	}
+
+	return start, offset, true
+}
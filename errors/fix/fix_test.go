@@ -0,0 +1,209 @@
+package fix
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bsido/go-errors/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const source = `fn main() {
+    for foo in Foo {}
+    let x = bar(a, b);
+}
+`
+
+func label(line, col int) errors.Label {
+	return errors.Label{File: "src/main.rs", Line: line, Column: col}
+}
+
+func Test_Apply(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		source   string
+		err      error
+		opts     []Option
+		expected string
+		applied  int
+		skipped  []string
+	}{
+		{
+			name:   "insertion and replacement",
+			source: source,
+			err: errors.New("test").
+				SuggestWithApplicability(label(2, 19), "", ".iter()", errors.ApplicabilityMachineApplicable).
+				Wrap(errors.New("wrapped").
+					SuggestWithApplicability(label(3, 13), "bar", "baz", errors.ApplicabilityMachineApplicable)),
+			expected: `fn main() {
+    for foo in Foo.iter() {}
+    let x = baz(a, b);
+}
+`,
+			applied: 2,
+		},
+		{
+			name:   "multiple lines",
+			source: source,
+			err: errors.New("test").
+				SuggestWithApplicability(label(2, 19), " {}\n    let x = bar(a, b);", " {}", errors.ApplicabilityMachineApplicable),
+			expected: `fn main() {
+    for foo in Foo {}
+}
+`,
+			applied: 1,
+		},
+		{
+			name:   "line breaks of windows",
+			source: "first\r\nsecond\r\n",
+			err: errors.New("test").
+				SuggestWithApplicability(label(1, 6), "\nsec", " ", errors.ApplicabilityMachineApplicable),
+			expected: "first ond\r\n",
+			applied:  1,
+		},
+		{
+			name:   "suggestions that may be incorrect are skipped by default",
+			source: source,
+			err: errors.New("test").
+				Suggest(label(2, 19), "", ".iter()"),
+			expected: source,
+			skipped:  []string{"the suggestion is MaybeIncorrect"},
+		},
+		{
+			name:   "applicability option",
+			source: source,
+			err: errors.New("test").
+				Suggest(label(2, 19), "", ".iter()"),
+			opts: []Option{WithApplicability(errors.ApplicabilityMachineApplicable, errors.ApplicabilityMaybeIncorrect)},
+			expected: `fn main() {
+    for foo in Foo.iter() {}
+    let x = bar(a, b);
+}
+`,
+			applied: 1,
+		},
+		{
+			name:   "overlapping suggestions",
+			source: source,
+			err: errors.New("test").
+				SuggestWithApplicability(label(3, 13), "bar(a", "baz(c", errors.ApplicabilityMachineApplicable).
+				SuggestWithApplicability(label(3, 17), "a, b", "b", errors.ApplicabilityMachineApplicable).
+				SuggestWithApplicability(label(3, 13), "", "x", errors.ApplicabilityMachineApplicable),
+			expected: `fn main() {
+    for foo in Foo {}
+    let x = baz(c, b);
+}
+`,
+			applied: 1,
+			skipped: []string{
+				"it overlaps with the suggestion at src/main.rs:3:13",
+				"it overlaps with the suggestion at src/main.rs:3:13",
+			},
+		},
+		{
+			name:   "the original text is not in the file",
+			source: source,
+			err: errors.New("test").
+				SuggestWithApplicability(label(3, 13), "foo", "baz", errors.ApplicabilityMachineApplicable).
+				SuggestWithApplicability(label(30, 1), "foo", "baz", errors.ApplicabilityMachineApplicable).
+				SuggestWithApplicability(errors.Label{File: "other.rs", Line: 1, Column: 1}, "", "x", errors.ApplicabilityMachineApplicable),
+			expected: source,
+			skipped: []string{
+				"the original text is not in the file",
+				"the location is outside of the file",
+				"the file is not available",
+			},
+		},
+		{
+			name:   "suggestions with a negative span",
+			source: "hello",
+			err: errors.New("test").
+				SuggestWithApplicability(errors.Label{File: "src/main.rs", Line: 1, Column: 3, Span: -1}, "", "ZZ", errors.ApplicabilityMachineApplicable),
+			expected: "hello",
+			skipped:  []string{"the location is outside of the file"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string][]byte{"src/main.rs": []byte(tt.source)}
+
+			result := Apply(files, []error{tt.err}, tt.opts...)
+
+			fixed, ok := result.Files["src/main.rs"]
+			if !ok {
+				fixed = files["src/main.rs"]
+			}
+
+			assert.Equal(t, tt.expected, string(fixed))
+			assert.Equal(t, tt.source, string(files["src/main.rs"]), "the original content is not modified")
+			assert.Len(t, result.Applied, tt.applied)
+
+			var reasons []string
+			for _, skipped := range result.Skipped {
+				reasons = append(reasons, skipped.Reason)
+			}
+
+			assert.Equal(t, tt.skipped, reasons)
+		})
+	}
+}
+
+func Test_Diff(t *testing.T) {
+	source := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14"
+
+	err := errors.New("test").
+		SuggestWithApplicability(errors.Label{File: "numbers.txt", Line: 2, Column: 1}, "2", "two", errors.ApplicabilityMachineApplicable).
+		SuggestWithApplicability(errors.Label{File: "numbers.txt", Line: 5, Column: 1}, "5\n", "", errors.ApplicabilityMachineApplicable).
+		SuggestWithApplicability(errors.Label{File: "numbers.txt", Line: 14, Column: 3}, "", "!", errors.ApplicabilityMachineApplicable)
+
+	result := Apply(map[string][]byte{"numbers.txt": []byte(source)}, []error{err})
+
+	assert.Equal(t, `--- a/numbers.txt
++++ b/numbers.txt
+@@ -1,8 +1,7 @@
+ 1
+-2
++two
+ 3
+ 4
+-5
+ 6
+ 7
+ 8
+@@ -11,4 +10,4 @@
+ 11
+ 12
+ 13
+-14
+\ No newline at end of file
++14!
+\ No newline at end of file
+`, result.Diff())
+}
+
+func Test_Apply_Files(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.rs")
+	require.NoError(t, os.WriteFile(path, []byte(source), 0o600))
+
+	err := errors.New("test").
+		SuggestWithApplicability(errors.Label{File: path, Line: 2, Column: 19}, "", ".iter()", errors.ApplicabilityMachineApplicable).
+		SuggestWithApplicability(errors.Label{File: filepath.Join(dir, "missing.rs"), Line: 1, Column: 1}, "", "x", errors.ApplicabilityMachineApplicable)
+
+	result, applyErr := ApplyFiles([]error{err}, WithDryRun())
+	require.NoError(t, applyErr)
+	assert.Len(t, result.Applied, 1)
+	assert.Equal(t, "the file is not available", result.Skipped[0].Reason)
+
+	content, readErr := os.ReadFile(path)
+	require.NoError(t, readErr)
+	assert.Equal(t, source, string(content), "dry run does not write the files")
+
+	_, applyErr = ApplyFiles([]error{err})
+	require.NoError(t, applyErr)
+
+	content, readErr = os.ReadFile(path)
+	require.NoError(t, readErr)
+	assert.Contains(t, string(content), "for foo in Foo.iter() {}")
+}
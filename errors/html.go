@@ -0,0 +1,223 @@
+package errors
+
+import (
+	"html/template"
+	"maps"
+	"strings"
+)
+
+const (
+	funcSeverityClass = "severityClass"
+
+	// htmlErrorTemplate has the layout of errorTemplate, the colors are CSS classes of spans
+	// and the rendered errors are put into a pre element with the class "diagnostic"
+	htmlErrorTemplate = `{{- template "messagePrefix" . }}
+{{- $message := wrap .Indent .Message -}}
+<span class="message">: {{ index $message 0 }}
+{{- range slice $message 1 }}
+{{ . }}
+{{- end }}</span>
+{{- template "cause" . }}
+
+{{- template "snippets" . }}
+
+{{- template "notes" . }}
+
+{{- template "helps" . }}
+
+{{- template "suggestions" . }}
+
+{{- template "stack" . }}
+
+{{- template "wrapped" . }}`
+
+	htmlWrappedTemplate = `{{- define "wrapped" }}
+{{- range .Wrapped }}
+
+{{ render . }}
+{{- end }}
+{{- if .Truncated }}
+{{- $errors := "errors" }}
+{{- if eq .Truncated 1 }}{{ $errors = "error" }}{{ end }}
+
+<span class="gutter">... {{ .Truncated }} more {{ $errors }}</span>
+{{- end }}
+{{- end }}`
+
+	htmlWrappedTreeTemplate = `{{- define "wrapped" }}
+{{- range $i, $wrapped := .Wrapped }}
+{{- $lines := split (render $wrapped) "\n" }}
+{{- if eq (len (slice $.Wrapped $i)) 1 }}
+<span class="gutter">╰─▶ </span>{{ index $lines 0 }}
+{{- range slice $lines 1 }}
+{{ if . }}    {{ . }}{{ end }}
+{{- end }}
+{{- else }}
+<span class="gutter">├─▶ </span>{{ index $lines 0 }}
+{{- range slice $lines 1 }}
+<span class="gutter">│</span>{{ if . }}   {{ . }}{{ end }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- if .Truncated }}
+{{- $errors := "errors" }}
+{{- if eq .Truncated 1 }}{{ $errors = "error" }}{{ end }}
+<span class="gutter">╰─▶ ... {{ .Truncated }} more {{ $errors }}</span>
+{{- end }}
+{{- end }}`
+
+	htmlCauseTemplate = `{{- define "cause" }}
+{{- if .Cause }}
+{{- $cause := split (render .Cause) "\n" }}
+  <span class="gutter">--&gt; </span>{{ index $cause 0 }}
+  {{- range $line := slice $cause 1 }}
+   <span class="gutter">| </span>{{ . }}
+  {{- end }}
+{{- end }}
+{{- end }}`
+
+	htmlSnippetsTemplate = `{{- define "snippets" }}
+{{- range .Snippets }}
+{{ .Indent }}<span class="gutter">{{ .Arrow }}</span> {{ .Location }}
+{{- range .Lines }}
+{{ if .Gap }}<span class="gutter">...</span>{{ else }}<span class="gutter">{{ .Number }} |</span>{{ end }}
+{{- range .Segments }}
+	{{- if eq .Style "primary" }}<span class="label primary {{ severityClass $.Severity }}">{{ .Text }}</span>
+	{{- else if eq .Style "secondary" }}<span class="label secondary">{{ .Text }}</span>
+	{{- else }}{{ .Text }}
+	{{- end }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- end }}`
+
+	htmlSuggestionsTemplate = `{{- define "suggestions" }}
+{{- range .Suggestions }}
+{{- if .Lines }}
+<span class="help">help</span>: {{ .Message }}
+{{ .Indent }} <span class="gutter">|</span>
+{{- range .Lines }}
+<span class="gutter">{{ .Number }}</span> {{ if eq .Marker "+" }}<span class="added">+</span>{{ else if eq .Marker "-" }}<span class="removed">-</span>{{ else }}<span class="gutter">{{ .Marker }}</span>{{ end }}
+{{- range .Segments }}
+	{{- if eq .Style "added" }}<span class="added">{{ .Text }}</span>
+	{{- else if eq .Style "removed" }}<span class="removed">{{ .Text }}</span>
+	{{- else }}{{ .Text }}
+	{{- end }}
+{{- end }}
+{{- end }}
+{{- else if .Replacement }}
+   <span class="gutter">= </span><span class="help">help</span>: {{ .Message }}: <code>{{ .Replacement }}</code>
+{{- else }}
+   <span class="gutter">= </span><span class="help">help</span>: {{ .Message }}: remove <code>{{ .Original }}</code>
+{{- end }}
+{{- end }}
+{{- end }}`
+
+	htmlStackTemplate = `{{- define "stack" }}
+{{- if .Stack }}
+   <span class="gutter">= </span><span class="stack">stack</span>:
+   {{- range .Stack }}
+           {{ .Function }}
+               {{ .File }}:{{ .Line }}
+   {{- end }}
+{{- end }}
+{{- end }}`
+
+	htmlMessagePrefixTemplate = `{{- define "messagePrefix" }}
+	{{- if and .Code (not (isBug .Severity)) -}}
+		<span class="{{ severityClass .Severity }}">{{ .Severity }}[<span class="code">{{ .Code }}</span>]</span>
+	{{- else -}}
+		<span class="{{ severityClass .Severity }}">{{ .Severity }}</span>
+	{{- end }}
+{{- end }}`
+
+	htmlNotesTemplate = `{{- define "notes" }}
+{{- range $note := .Notes }}
+{{- $lines := wrap 11 $note }}
+   <span class="gutter">= </span><span class="note">note</span>: {{ index $lines 0 }}
+{{- range slice $lines 1 }}
+           {{ . }}
+{{- end }}
+{{- end }}
+{{- end }}`
+
+	htmlHelpsTemplate = `{{- define "helps" }}
+{{- range $help := .Helps }}
+{{- $lines := wrap 11 $help }}
+   <span class="gutter">= </span><span class="help">help</span>: {{ index $lines 0 }}
+{{- range slice $lines 1 }}
+           {{ . }}
+{{- end }}
+{{- end }}
+{{- end }}`
+)
+
+var severityClasses = map[Severity]string{
+	SeverityError:   "error",
+	SeverityWarning: "warning",
+	SeverityNote:    "note",
+	SeverityInfo:    "info",
+	SeverityBug:     "bug",
+}
+
+// severityClass returns the CSS class of the severity.
+func severityClass(severity Severity) string {
+	if class, ok := severityClasses[severity]; ok {
+		return class
+	}
+
+	return "error"
+}
+
+// htmlSplit splits the rendered HTML of an error, other values are escaped before they are split.
+func htmlSplit(s any, sep string) []template.HTML {
+	text, ok := s.(template.HTML)
+	if !ok {
+		text = template.HTML(template.HTMLEscaper(s))
+	}
+
+	var result []template.HTML
+	for _, line := range strings.Split(string(text), sep) {
+		result = append(result, template.HTML(line))
+	}
+
+	return result
+}
+
+func defaultHTMLDefinitions() map[TemplateDefinition]string {
+	return map[TemplateDefinition]string{
+		TemplateDefinitionMessagePrefix: htmlMessagePrefixTemplate,
+		TemplateDefinitionCause:         htmlCauseTemplate,
+		TemplateDefinitionNotes:         htmlNotesTemplate,
+		TemplateDefinitionHelps:         htmlHelpsTemplate,
+		TemplateDefinitionSnippets:      htmlSnippetsTemplate,
+		TemplateDefinitionStack:         htmlStackTemplate,
+		TemplateDefinitionWrapped:       htmlWrappedTemplate,
+		TemplateDefinitionSuggestions:   htmlSuggestionsTemplate,
+	}
+}
+
+// WithHTMLTemplateDefinition replaces a part of the HTML rendering like WithTemplateDefinition does for the text,
+// the definition is an html/template that escapes the values.
+func WithHTMLTemplateDefinition(name TemplateDefinition, definition string) InitOption {
+	return func(opts *initOptions) {
+		opts.htmlDefinitions[name] = definition
+	}
+}
+
+// newHTMLTemplate creates the HTML template with the functions of the plain text templates.
+func newHTMLTemplate(definitions map[TemplateDefinition]string, fns template.FuncMap) *template.Template {
+	fns = maps.Clone(fns)
+	fns[funcSplit] = htmlSplit
+	fns[funcSeverityClass] = severityClass
+	// replaced by the copies of the template that are bound to a renderer, see templatePool
+	fns[funcRender] = func(err error) template.HTML { return template.HTML(template.HTMLEscapeString(err.Error())) }
+
+	result := template.New(templateNameError).Funcs(fns)
+
+	for _, def := range definitions {
+		result = template.Must(result.Parse(def))
+	}
+
+	return template.Must(result.Parse(htmlErrorTemplate))
+}
@@ -0,0 +1,154 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HTML(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name: "message and code are escaped",
+			err:  New("<script>alert('x')</script>").Code(277),
+			expected: `<pre class="diagnostic"><span class="error">error[<span class="code">E0277</span>]</span>` +
+				`<span class="message">: &lt;script&gt;alert(&#39;x&#39;)&lt;/script&gt;</span></pre>`,
+		},
+		{
+			name: "warning with notes and helps",
+			err: New("unused <T>").
+				SetSeverity(SeverityWarning).
+				Note("a & b").
+				Help("remove it"),
+			expected: `<pre class="diagnostic"><span class="warning">warning</span><span class="message">: unused &lt;T&gt;</span>
+   <span class="gutter">= </span><span class="note">note</span>: a &amp; b
+   <span class="gutter">= </span><span class="help">help</span>: remove it</pre>`,
+		},
+		{
+			name: "snippet",
+			err: New("test").
+				Snippet("src/main.rs", 3, iteratorSource).
+				Label("src/main.rs", 4, 16, 3, "").
+				SecondaryLabel("src/main.rs", 5, 10, 3, ""),
+			expected: `<pre class="diagnostic"><span class="error">error</span><span class="message">: test</span>
+  <span class="gutter">--&gt;</span> src/main.rs:4:16
+<span class="gutter">   |</span>
+<span class="gutter"> 4 |</span>     for foo in Foo {}
+<span class="gutter">   |</span>                <span class="label primary error">^^^</span>
+<span class="gutter"> 5 |</span>     let x = bar(a, b);
+<span class="gutter">   |</span>             <span class="label secondary">---</span></pre>`,
+		},
+		{
+			name: "suggestion",
+			err: New("test").
+				Snippet("src/main.rs", 3, iteratorSource).
+				Suggest(Label{File: "src/main.rs", Line: 5, Column: 10}, "bar", "baz").
+				Suggest(Label{File: "src/lib.rs", Line: 1, Column: 1}, "", "<T>"),
+			expected: `<pre class="diagnostic"><span class="error">error</span><span class="message">: test</span>
+<span class="help">help</span>: try this
+   <span class="gutter">|</span>
+<span class="gutter"> 5</span> <span class="gutter">|</span>     let x = <span class="added">baz</span>(a, b);
+<span class="gutter">  </span> <span class="gutter">|</span>             <span class="added">~~~</span>
+   <span class="gutter">= </span><span class="help">help</span>: try this: <code>&lt;T&gt;</code></pre>`,
+		},
+		{
+			name: "causes and wrapped errors",
+			err: New("test").
+				Cause(New("cause").Note("note")).
+				Wrap(fmt.Errorf("plain <error>")),
+			expected: `<pre class="diagnostic"><span class="error">error</span><span class="message">: test</span>
+  <span class="gutter">--&gt; </span><span class="error">error</span><span class="message">: cause</span>
+   <span class="gutter">| </span>   <span class="gutter">= </span><span class="note">note</span>: note
+
+plain &lt;error&gt;</pre>`,
+		},
+		{
+			name: "tree layout",
+			err: NewInitializer(WithLayout(LayoutTree)).NewError("test").
+				Wrap(New("first").Note("note")).
+				Wrap(New("second")),
+			expected: `<pre class="diagnostic"><span class="error">error</span><span class="message">: test</span>
+<span class="gutter">├─▶ </span><span class="error">error</span><span class="message">: first</span>
+<span class="gutter">│</span>      <span class="gutter">= </span><span class="note">note</span>: note
+<span class="gutter">╰─▶ </span><span class="error">error</span><span class="message">: second</span></pre>`,
+		},
+		{
+			name:     "plain error",
+			err:      io.ErrUnexpectedEOF,
+			expected: `<pre class="diagnostic">unexpected EOF</pre>`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Sprint(tt.err, RenderOptions{Format: FormatHTML}))
+		})
+	}
+}
+
+func Test_HTML_Template_Definition(t *testing.T) {
+	init := NewInitializer(
+		WithHTMLTemplateDefinition(TemplateDefinitionNotes, `{{- define "notes" }}
+{{- range .Notes }}
+<ul class="notes"><li>{{ . }}</li></ul>
+{{- end }}
+{{- end }}`),
+		WithAdditionalFunction("shout", func(s string) string { return s + "!" }),
+		WithHTMLTemplateDefinition(TemplateDefinitionHelps, `{{- define "helps" }}
+{{- range .Helps }}
+<b>{{ shout . }}</b>
+{{- end }}
+{{- end }}`),
+	)
+
+	err := init.NewError("test").Note("a < b").Help("help")
+
+	assert.Equal(t, `<pre class="diagnostic"><span class="error">error</span><span class="message">: test</span>
+<ul class="notes"><li>a &lt; b</li></ul>
+<b>help!</b></pre>`, Sprint(err, RenderOptions{Format: FormatHTML}))
+
+	assert.Equal(t, `error: test
+   = note: a < b
+   = help: help`, Sprint(err, RenderOptions{Color: ColorNever}), "the text templates are not changed")
+}
+
+func Test_HTML_Emitter(t *testing.T) {
+	var buf bytes.Buffer
+
+	emitter := NewEmitter(&buf, WithRenderOptions(RenderOptions{Format: FormatHTML}))
+	emitter.Emit(New("test"))
+	_ = emitter.Finish()
+
+	assert.Equal(t, `<pre class="diagnostic"><span class="error">error</span><span class="message">: test</span></pre>
+
+<pre class="diagnostic"><span class="error">error</span><span class="message">: aborting due to 1 previous error</span></pre>
+`, buf.String())
+}
+
+func Test_HTML_Without_Colors(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = original }()
+
+	init := NewInitializer(WithHTMLTemplateDefinition(TemplateDefinitionNotes, `{{- define "notes" }}
+{{- range .Notes }}
+<b>{{ bold . }}</b> {{ severityColor $.Severity "!" }}
+{{- end }}
+{{- end }}`))
+
+	err := init.NewError("test").Note("a < b").Wrap(init.NewError("wrapped").Note("note"))
+
+	assert.Equal(t, `<pre class="diagnostic"><span class="error">error</span><span class="message">: test</span>
+<b>a &lt; b</b> !
+
+<span class="error">error</span><span class="message">: wrapped</span>
+<b>note</b> !</pre>`, Sprint(err, RenderOptions{Format: FormatHTML, Color: ColorAlways}))
+
+	assert.Contains(t, Sprint(err, RenderOptions{Color: ColorAlways}), "\x1b[", "the text is still colored")
+}
@@ -6,50 +6,105 @@ import (
 	"text/template"
 )
 
+// Init creates errors that are rendered with its templates.
+// It is immutable, the options are applied when it is created.
 type Init struct {
-	funcMap  template.FuncMap
-	template *template.Template
+	// funcMap has the functions set by the options
+	funcMap template.FuncMap
 
-	definitions map[TemplateDefinition]string
+	// the templates of the colored and the plain text and of the HTML
+	colorTemplates *templatePool
+	plainTemplates *templatePool
+	htmlTemplates  *templatePool
+
+	definitions     map[TemplateDefinition]string
+	htmlDefinitions map[TemplateDefinition]string
+
+	stackDepth int
+	registry   *Registry
+	codeFormat CodeFormat
+	maxDepth   int
 }
 
 func NewInitializer(opts ...InitOption) *Init {
-	options := newOptions(opts)
+	return newInit(newOptions(opts))
+}
 
+func newInit(options *initOptions) *Init {
 	return &Init{
-		funcMap:  options.funcMap,
-		template: newTemplate(options.definitions, options.funcMap),
+		funcMap: options.funcMap,
+
+		colorTemplates: newTextTemplatePool(newTemplate(options.definitions, colorPalette.funcMap(options.funcMap))),
+		plainTemplates: newTextTemplatePool(newTemplate(options.definitions, plainPalette.funcMap(options.funcMap))),
+		htmlTemplates:  newHTMLTemplatePool(newHTMLTemplate(options.htmlDefinitions, plainPalette.funcMap(options.funcMap))),
+
+		definitions:     options.definitions,
+		htmlDefinitions: options.htmlDefinitions,
 
-		definitions: options.definitions,
+		stackDepth: options.stackDepth,
+		registry:   options.registry,
+		codeFormat: options.codeFormat,
+		maxDepth:   options.maxDepth,
 	}
 }
 
+// with returns a copy of the initializer with the options applied.
+func (b *Init) with(opts ...InitOption) *Init {
+	options := &initOptions{
+		funcMap:         maps.Clone(b.funcMap),
+		definitions:     maps.Clone(b.definitions),
+		htmlDefinitions: maps.Clone(b.htmlDefinitions),
+		stackDepth:      b.stackDepth,
+		registry:        b.registry,
+		codeFormat:      b.codeFormat,
+		maxDepth:        b.maxDepth,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return newInit(options)
+}
+
 func (b *Init) NewError(message string) *Error {
-	return New(message).initializer(b)
+	return newError(message, b, 1).initializer(b)
 }
 
 func (b *Init) NewErrorf(format string, args ...any) *Error {
-	return b.NewError(fmt.Sprintf(format, args...))
+	return newError(fmt.Sprintf(format, args...), b, 1).initializer(b)
 }
 
 func (b *Init) Extend(original *Error) *Error {
 	return original.initializer(b)
 }
 
-type templateOptions struct {
-	funcMap     template.FuncMap
-	definitions map[TemplateDefinition]string
+type initOptions struct {
+	funcMap         template.FuncMap
+	definitions     map[TemplateDefinition]string
+	htmlDefinitions map[TemplateDefinition]string
+	stackDepth      int
+	registry        *Registry
+	codeFormat      CodeFormat
+	maxDepth        int
 }
 
-func newOptions(opts []InitOption) *templateOptions {
-	result := &templateOptions{
-		funcMap: maps.Clone(funcMap),
+func newOptions(opts []InitOption) *initOptions {
+	result := &initOptions{
+		funcMap: template.FuncMap{},
 		definitions: map[TemplateDefinition]string{
 			TemplateDefinitionMessagePrefix: messagePrefixTemplate,
 			TemplateDefinitionCause:         causeTemplate,
 			TemplateDefinitionNotes:         notesTemplate,
 			TemplateDefinitionHelps:         helpsTemplate,
+			TemplateDefinitionSnippets:      snippetsTemplate,
+			TemplateDefinitionStack:         stackTemplate,
+			TemplateDefinitionWrapped:       wrappedTemplate,
+			TemplateDefinitionSuggestions:   suggestionsTemplate,
+			TemplateDefinitionMarkdown:      markdownTemplate,
 		},
+		htmlDefinitions: defaultHTMLDefinitions(),
+		codeFormat:      DefaultCodeFormat,
 	}
 
 	for _, opt := range opts {
@@ -59,22 +114,25 @@ func newOptions(opts []InitOption) *templateOptions {
 	return result
 }
 
-type InitOption func(*templateOptions)
+type InitOption func(*initOptions)
 
+// WithFunctions replaces the functions of the templates set by the previous options.
+// The default colors and split are used if they are not in the map,
+// the functions that the default templates depend on internally cannot be replaced.
 func WithFunctions(funcMap template.FuncMap) InitOption {
-	return func(opts *templateOptions) {
-		opts.funcMap = funcMap
+	return func(opts *initOptions) {
+		opts.funcMap = maps.Clone(funcMap)
 	}
 }
 
 func WithAdditionalFunction(name string, fn any) InitOption {
-	return func(opts *templateOptions) {
+	return func(opts *initOptions) {
 		opts.funcMap[name] = fn
 	}
 }
 
 func WithAdditionalFunctions(funcs template.FuncMap) InitOption {
-	return func(opts *templateOptions) {
+	return func(opts *initOptions) {
 		for name, fn := range funcs {
 			opts.funcMap[name] = fn
 		}
@@ -82,7 +140,66 @@ func WithAdditionalFunctions(funcs template.FuncMap) InitOption {
 }
 
 func WithTemplateDefinition(name TemplateDefinition, definition string) InitOption {
-	return func(opts *templateOptions) {
+	return func(opts *initOptions) {
 		opts.definitions[name] = definition
 	}
 }
+
+// WithStackTrace captures at most depth frames of the call stack when an error is created.
+// Stack traces are disabled by default.
+func WithStackTrace(depth int) InitOption {
+	return func(opts *initOptions) {
+		opts.stackDepth = depth
+	}
+}
+
+// Layout is the arrangement of the wrapped errors.
+type Layout int
+
+const (
+	// LayoutFlat renders the wrapped errors after each other separated by empty lines.
+	LayoutFlat Layout = iota
+	// LayoutTree renders the wrapped errors as a tree that shows which error wraps which.
+	LayoutTree
+)
+
+// WithLayout sets the text and HTML template definitions of the wrapped errors to the layout.
+func WithLayout(layout Layout) InitOption {
+	return func(opts *initOptions) {
+		if layout == LayoutTree {
+			opts.definitions[TemplateDefinitionWrapped] = wrappedTreeTemplate
+			opts.htmlDefinitions[TemplateDefinitionWrapped] = htmlWrappedTreeTemplate
+		} else {
+			opts.definitions[TemplateDefinitionWrapped] = wrappedTemplate
+			opts.htmlDefinitions[TemplateDefinitionWrapped] = htmlWrappedTemplate
+		}
+	}
+}
+
+// WithMaxDepth limits how deep the tree of the wrapped errors is rendered,
+// the errors below the limit are summarized as "... N more errors". 0 renders the whole tree.
+func WithMaxDepth(depth int) InitOption {
+	return func(opts *initOptions) {
+		opts.maxDepth = depth
+	}
+}
+
+// WithRegistry adds the help about the explanation of the registered error codes
+// and checks the codes if the registry is strict.
+func WithRegistry(registry *Registry) InitOption {
+	return func(opts *initOptions) {
+		opts.registry = registry
+	}
+}
+
+// WithCodeFormat changes how the numeric codes are rendered by Code, e.g. "AUTH-" with width 0 renders AUTH-123.
+// It panics if the width is negative.
+func WithCodeFormat(prefix string, width int) InitOption {
+	if width < 0 {
+		panic(fmt.Sprintf("negative code width: %d", width))
+	}
+
+	return func(opts *initOptions) {
+		opts.codeFormat = CodeFormat{Prefix: prefix, Width: width}
+	}
+}
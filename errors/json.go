@@ -0,0 +1,132 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+)
+
+const (
+	jsonTypeDiagnostic = "diagnostic"
+	jsonTypeError      = "error"
+)
+
+// jsonError is the stable JSON schema of the errors.
+// Errors that are not *Error are represented with their message only.
+type jsonError struct {
+	Type        string         `json:"type"`
+	Level       Severity       `json:"level"`
+	Message     string         `json:"message"`
+	Code        string         `json:"code,omitempty"`
+	Cause       *jsonError     `json:"cause,omitempty"`
+	Notes       []string       `json:"notes,omitempty"`
+	Helps       []string       `json:"helps,omitempty"`
+	Snippets    []Snippet      `json:"snippets,omitempty"`
+	Labels      []Label        `json:"labels,omitempty"`
+	Suggestions []Suggestion   `json:"suggestions,omitempty"`
+	Data        map[string]any `json:"data,omitempty"`
+	Stack       []Frame        `json:"stack,omitempty"`
+	Wrapped     []*jsonError   `json:"wrapped,omitempty"`
+}
+
+// ToJSON returns the JSON representation of any error.
+func ToJSON(err error) ([]byte, error) {
+	return json.Marshal(toJSON(err, nil))
+}
+
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toJSON(e, nil))
+}
+
+func (e *Error) UnmarshalJSON(data []byte) error {
+	var result jsonError
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+
+	*e = *result.toError()
+
+	return nil
+}
+
+// toJSON converts the tree of the error, path is the chain of the errors being converted.
+// Errors that wrap themselves are converted to plain errors with the cycle marker.
+func toJSON(err error, path []*Error) *jsonError {
+	if err == nil {
+		return nil
+	}
+
+	if e, ok := err.(*Error); ok {
+		if slices.Contains(path, e) {
+			return &jsonError{
+				Type:    jsonTypeError,
+				Level:   SeverityError,
+				Message: cycleMarker,
+			}
+		}
+
+		return e.toJSON(append(slices.Clip(path), e))
+	}
+
+	return &jsonError{
+		Type:    jsonTypeError,
+		Message: err.Error(),
+	}
+}
+
+func (e *Error) toJSON(path []*Error) *jsonError {
+	result := &jsonError{
+		Type:        jsonTypeDiagnostic,
+		Level:       e.severity,
+		Message:     e.message,
+		Code:        e.code,
+		Cause:       toJSON(e.cause, path),
+		Notes:       e.notes,
+		Helps:       e.helps,
+		Snippets:    e.snippets,
+		Labels:      e.labels,
+		Suggestions: e.suggestions,
+		Data:        e.additionalTemplateData,
+		Stack:       e.stack,
+	}
+
+	for _, err := range e.wrapped {
+		if err != nil {
+			result.Wrapped = append(result.Wrapped, toJSON(err, path))
+		}
+	}
+
+	return result
+}
+
+func (j *jsonError) toError() *Error {
+	result := New(j.Message)
+
+	result.code = j.Code
+	result.severity = j.Level
+	result.notes = j.Notes
+	result.helps = j.Helps
+	result.snippets = j.Snippets
+	result.labels = j.Labels
+	result.suggestions = j.Suggestions
+	result.additionalTemplateData = j.Data
+	result.stack = j.Stack
+
+	if j.Cause != nil {
+		result.cause = j.Cause.toPlainError()
+	}
+
+	for _, wrapped := range j.Wrapped {
+		result.wrapped = append(result.wrapped, wrapped.toPlainError())
+	}
+
+	return result
+}
+
+func (j *jsonError) toPlainError() error {
+	if j.Type == jsonTypeError {
+		return errors.New(j.Message)
+	}
+
+	return j.toError()
+}
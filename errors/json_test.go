@@ -0,0 +1,92 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_JSON(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "plain error",
+			err:      io.EOF,
+			expected: `{"type":"error","level":"error","message":"EOF"}`,
+		},
+		{
+			name:     "message only",
+			err:      New("test"),
+			expected: `{"type":"diagnostic","level":"error","message":"test"}`,
+		},
+		{
+			name: "every field",
+			err: New("test").
+				Code(277).
+				Cause(io.EOF).
+				Note("note").
+				Help("help").
+				Snippet("src/main.rs", 3, "fn main() {}").
+				Label("src/main.rs", 3, 4, 4, "label").
+				AdditionalTemplateData(map[string]any{"Key": "value"}).
+				Wrap(New("wrapped").SetSeverity(SeverityWarning).Cause(New("cause"))),
+			expected: `{
+				"type": "diagnostic",
+				"level": "error",
+				"message": "test",
+				"code": "E0277",
+				"cause": {"type": "error", "level": "error", "message": "EOF"},
+				"notes": ["note"],
+				"helps": ["help"],
+				"snippets": [{"file": "src/main.rs", "first_line": 3, "source": "fn main() {}"}],
+				"labels": [{"file": "src/main.rs", "line": 3, "column": 4, "span": 4, "text": "label", "primary": true}],
+				"data": {"Key": "value"},
+				"wrapped": [{
+					"type": "diagnostic",
+					"level": "warning",
+					"message": "wrapped",
+					"cause": {"type": "diagnostic", "level": "error", "message": "cause"}
+				}]
+			}`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ToJSON(tt.err)
+			require.NoError(t, err)
+
+			assert.JSONEq(t, tt.expected, string(result))
+		})
+	}
+}
+
+func Test_JSON_RoundTrip(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	err := New("'Foo' is not an iterator").
+		Code(277).
+		Cause(errors.New("plain cause")).
+		Snippet("src/main.rs", 3, iteratorSource).
+		Label("src/main.rs", 4, 16, 3, "'Foo' is not an iterator").
+		Note("maybe try calling '.iter()' or a similar method").
+		Help("the trait 'std::iter::Iterator' is not implemented for 'Foo'").
+		Wrap(New("wrapped").Code(1).SetSeverity(SeverityBug).Cause(New("cause")))
+
+	data, marshalErr := json.Marshal(err)
+	require.NoError(t, marshalErr)
+
+	var result *Error
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	assert.Equal(t, err.Error(), result.Error())
+	assert.True(t, errors.Is(result, New("wrapped").Code(1)))
+}
@@ -0,0 +1,95 @@
+package errors
+
+import (
+	"strings"
+)
+
+const (
+	templateNameMarkdown = "markdown"
+
+	funcMarkdown = "markdownEscape"
+	funcSection  = "section"
+
+	// dataHeading is the Markdown heading of the nested errors, the outermost error has none
+	dataHeading = "Heading"
+	// dataFence is the code fence of the source code, it is longer than the runs of backticks in the code
+	dataFence = "Fence"
+
+	// markdownTemplate renders the source code in a fenced block, the notes and helps as a list
+	// and the causes and wrapped errors as nested sections with headings by their depth,
+	// the plain causes are text
+	markdownTemplate = `{{- define "markdown" }}
+{{- $header := print .Severity }}
+{{- if and .Code (not (isBug .Severity)) }}{{ $header = print .Severity "[" .Code "]" }}{{ end }}
+{{- $title := markdownEscape (print $header ": " .Message) }}
+{{- if .Heading }}{{ print .Heading " " $title }}{{ else }}{{ print "**" $title "**" }}{{ end }}
+{{- if or .Snippets .Suggestions .Stack }}
+
+{{ .Fence }}text
+{{- template "snippets" . }}
+{{- template "suggestions" . }}
+{{- template "stack" . }}
+{{ .Fence }}
+{{- end }}
+{{- if or .Notes .Helps }}
+{{ range .Notes }}
+- **note**: {{ markdownEscape . }}
+{{- end }}
+{{- range .Helps }}
+- **help**: {{ markdownEscape . }}
+{{- end }}
+{{- end }}
+{{- if .Cause }}
+
+caused by:
+
+{{ render .Cause }}
+{{- end }}
+{{- if .Wrapped }}
+
+wrapped errors:
+{{- range .Wrapped }}
+
+{{ section . }}
+{{- end }}
+{{- end }}
+{{- if .Truncated }}
+{{- $errors := "errors" }}
+{{- if eq .Truncated 1 }}{{ $errors = "error" }}{{ end }}
+
+*... {{ .Truncated }} more {{ $errors }}*
+{{- end }}
+{{- end }}`
+)
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "|", `\|`, "~", `\~`,
+)
+
+// markdownEscape escapes the characters that would be formatting in Markdown.
+func markdownEscape(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// markdownHeading returns the heading of the errors at the depth, the deepest level is 6.
+func markdownHeading(depth int) string {
+	return strings.Repeat("#", min(depth, 6))
+}
+
+// fence returns a code fence that is longer than the runs of backticks in the code.
+func fence(code string) string {
+	longest, run := 0, 0
+
+	for _, r := range code {
+		if r != '`' {
+			run = 0
+			continue
+		}
+
+		run++
+		longest = max(longest, run)
+	}
+
+	return strings.Repeat("`", max(3, longest+1))
+}
@@ -0,0 +1,128 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Markdown(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "message is escaped",
+			err:      New("'*Foo*' is not an <iterator>").Code(277),
+			expected: `**error\[E0277\]: '\*Foo\*' is not an \<iterator\>**`,
+		},
+		{
+			name: "notes and helps are lists",
+			err: New("unused variable").
+				SetSeverity(SeverityWarning).
+				Note("first_note").
+				Note("second note").
+				Help("remove it"),
+			expected: `**warning: unused variable**
+
+- **note**: first\_note
+- **note**: second note
+- **help**: remove it`,
+		},
+		{
+			name: "source code is fenced",
+			err: New("'Foo' is not an iterator").
+				Snippet("src/main.rs", 3, iteratorSource).
+				Label("src/main.rs", 4, 16, 3, "'Foo' is not an iterator").
+				Suggest(Label{File: "src/main.rs", Line: 4, Column: 19, Text: "call '.iter()'"}, "", ".iter()"),
+			expected: "**error: 'Foo' is not an iterator**\n\n```text" + `
+  --> src/main.rs:4:16
+   |
+ 4 |     for foo in Foo {}
+   |                ^^^ 'Foo' is not an iterator
+   |
+help: call '.iter()'
+   |
+ 4 |     for foo in Foo.iter() {}
+   |                   +++++++
+` + "```",
+		},
+		{
+			name: "the fence is longer than the backticks of the code",
+			err: New("test").
+				Snippet("README.md", 1, "```go\nfoo()\n```").
+				Label("README.md", 2, 1, 3, "").
+				Suggest(Label{File: "README.md", Line: 1, Column: 1}, "", "````"),
+			// the suggestion makes a run of 7 backticks
+			expected: "**error: test**\n\n````````text" + `
+  --> README.md:2:1
+   |
+ 2 | foo()
+   | ^^^
+   |
+help: try this
+   |
+ 1 | ` + "```````go" + `
+   | ++++
+` + "````````",
+		},
+		{
+			name: "causes and wrapped errors are nested sections, plain causes are text",
+			err: New("test").
+				Cause(io.EOF).
+				Wrap(New("first").Cause(New("cause").Note("note"))).
+				Wrap(fmt.Errorf("<plain>")),
+			expected: `**error: test**
+
+caused by:
+
+EOF
+
+wrapped errors:
+
+## error: first
+
+caused by:
+
+### error: cause
+
+- **note**: note
+
+## \<plain\>`,
+		},
+		{
+			name: "maximum depth",
+			err: NewInitializer(WithMaxDepth(1)).NewError("test").
+				Wrap(New("first").Wrap(New("second"))),
+			expected: `**error: test**
+
+*... 2 more errors*`,
+		},
+		{
+			name:     "plain error",
+			err:      fmt.Errorf("a_b"),
+			expected: `a\_b`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Sprint(tt.err, RenderOptions{Format: FormatMarkdown}))
+		})
+	}
+}
+
+func Test_Markdown_Template_Definition(t *testing.T) {
+	init := NewInitializer(WithTemplateDefinition(TemplateDefinitionMarkdown, `{{- define "markdown" }}
+{{- print "### " (markdownEscape .Message) }}
+{{- range .Notes }}
+* {{ markdownEscape . }}
+{{- end }}
+{{- end }}`))
+
+	err := init.NewError("not_found").Note("note")
+
+	assert.Equal(t, "### not\\_found\n* note", Sprint(err, RenderOptions{Format: FormatMarkdown}))
+	assert.Equal(t, "error: not_found\n   = note: note", Sprint(err, RenderOptions{Color: ColorNever}))
+}
@@ -0,0 +1,158 @@
+package errors
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// CodeDefinition is the long-form description of an error code.
+type CodeDefinition struct {
+	Title string
+	// Explanation is written in Markdown.
+	Explanation string
+	Example     string
+}
+
+// Registry stores the definitions of the error codes, similarly to `rustc --explain`.
+// It is safe for concurrent use.
+type Registry struct {
+	command string
+	strict  bool
+
+	mu          sync.RWMutex
+	definitions map[string]CodeDefinition
+}
+
+type RegistryOption func(*Registry)
+
+// WithStrictCodes makes Code panic when the code is not registered.
+func WithStrictCodes() RegistryOption {
+	return func(r *Registry) {
+		r.strict = true
+	}
+}
+
+// NewRegistry creates a registry, command is the program that explains the codes with its --explain flag.
+// If the command is empty the errors do not get the help line about the explanation.
+func NewRegistry(command string, opts ...RegistryOption) *Registry {
+	result := &Registry{
+		command:     command,
+		definitions: make(map[string]CodeDefinition),
+	}
+
+	for _, opt := range opts {
+		opt(result)
+	}
+
+	return result
+}
+
+// Register adds the definition of a code as it is rendered, e.g. "E0277".
+// It panics if the code is already registered.
+func (r *Registry) Register(code string, definition CodeDefinition) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.definitions[code]; ok {
+		panic(fmt.Sprintf("error code is already registered: %s", code))
+	}
+
+	r.definitions[code] = definition
+}
+
+func (r *Registry) Lookup(code string) (CodeDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	definition, ok := r.definitions[code]
+
+	return definition, ok
+}
+
+// Explain returns the Markdown description of the code.
+func (r *Registry) Explain(code string) (string, error) {
+	definition, ok := r.Lookup(code)
+	if !ok {
+		return "", Newf("no extended information for %s", code)
+	}
+
+	var sb strings.Builder
+
+	_, _ = fmt.Fprintf(&sb, "# %s: %s\n", code, definition.Title)
+
+	if definition.Explanation != "" {
+		_, _ = fmt.Fprintf(&sb, "\n%s\n", strings.TrimSpace(definition.Explanation))
+	}
+
+	if definition.Example != "" {
+		_, _ = fmt.Fprintf(&sb, "\n```\n%s\n```\n", strings.TrimSpace(definition.Example))
+	}
+
+	return sb.String(), nil
+}
+
+// Codes returns the registered codes in order.
+func (r *Registry) Codes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]string, 0, len(r.definitions))
+	for code := range r.definitions {
+		result = append(result, code)
+	}
+
+	slices.Sort(result)
+
+	return result
+}
+
+// Validate returns an error if any error in the tree of err has an unregistered code.
+// Useful in tests to make sure that every code that is used has an explanation.
+func (r *Registry) Validate(err error) error {
+	var unregistered []string
+
+	Walk(err, func(e *Error) bool {
+		if e.code == "" {
+			return true
+		}
+
+		if _, ok := r.Lookup(e.code); !ok && !slices.Contains(unregistered, e.code) {
+			unregistered = append(unregistered, e.code)
+		}
+
+		return true
+	})
+
+	if len(unregistered) == 0 {
+		return nil
+	}
+
+	return Newf("unregistered error codes: %s", strings.Join(unregistered, ", "))
+}
+
+// explainHelp returns the help line about the explanation of the code,
+// it is empty if the code is not registered.
+func (r *Registry) explainHelp(code string) string {
+	if r == nil || r.command == "" || code == "" {
+		return ""
+	}
+
+	if _, ok := r.Lookup(code); !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("for more information about this error, try `%s --explain %s`", r.command, code)
+}
+
+// check panics if the registry is strict and the code is not registered.
+func (r *Registry) check(code string) {
+	if r == nil || !r.strict {
+		return
+	}
+
+	if _, ok := r.Lookup(code); !ok {
+		panic(fmt.Sprintf("unregistered error code: %s", code))
+	}
+}
@@ -0,0 +1,83 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRegistry(opts ...RegistryOption) *Registry {
+	registry := NewRegistry("mytool", opts...)
+
+	registry.Register("E0277", CodeDefinition{
+		Title:       "a type is not an iterator",
+		Explanation: "You tried to iterate over a value that does not implement `Iterator`.",
+		Example:     "for foo in Foo {}",
+	})
+
+	return registry
+}
+
+func Test_Registry_Explain(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	registry := newTestRegistry()
+
+	explanation, err := registry.Explain("E0277")
+	require.NoError(t, err)
+	assert.Equal(t, "# E0277: a type is not an iterator\n\n"+
+		"You tried to iterate over a value that does not implement `Iterator`.\n\n"+
+		"```\nfor foo in Foo {}\n```\n", explanation)
+
+	_, err = registry.Explain("E0001")
+	assert.EqualError(t, err, "error: no extended information for E0001")
+
+	assert.Equal(t, []string{"E0277"}, registry.Codes())
+	assert.Panics(t, func() { registry.Register("E0277", CodeDefinition{}) })
+}
+
+func Test_Registry_Help(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	init := NewInitializer(WithRegistry(newTestRegistry()))
+
+	assert.Equal(t, "error[E0277]: test\n"+
+		"   = help: for more information about this error, try `mytool --explain E0277`",
+		init.NewError("test").Code(277).Error())
+
+	assert.Equal(t, "error[E0278]: test", init.NewError("test").Code(278).Error())
+
+	SetRegistry(newTestRegistry())
+	defer Reset()
+
+	assert.Equal(t, "error[E0277]: test\n"+
+		"   = help: help\n"+
+		"   = help: for more information about this error, try `mytool --explain E0277`",
+		New("test").Code(277).Help("help").Error())
+}
+
+func Test_Registry_Strict(t *testing.T) {
+	init := NewInitializer(WithRegistry(newTestRegistry(WithStrictCodes())))
+
+	assert.NotPanics(t, func() { init.NewError("test").Code(277) })
+	assert.PanicsWithValue(t, "unregistered error code: E0278", func() { init.NewError("test").Code(278) })
+}
+
+func Test_Registry_Validate(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	registry := newTestRegistry()
+
+	assert.NoError(t, registry.Validate(New("test").Code(277).Wrap(New("no code"))))
+	assert.EqualError(t,
+		registry.Validate(New("test").Code(277).Wrap(New("wrapped").Code(1)).Cause(New("cause").Code(2))),
+		"error: unregistered error codes: E0002, E0001")
+}
@@ -0,0 +1,411 @@
+package errors
+
+import (
+	"fmt"
+	"html"
+	htmltemplate "html/template"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+type ColorMode int
+
+const (
+	// ColorAuto colors the output if the writer is a terminal,
+	// NO_COLOR disables and CLICOLOR_FORCE enables the colors regardless of the writer.
+	ColorAuto ColorMode = iota
+	ColorAlways
+	ColorNever
+)
+
+// Format is the markup of the rendered diagnostics.
+type Format int
+
+const (
+	FormatText Format = iota
+	// FormatHTML renders the diagnostics into pre elements with the class "diagnostic",
+	// the colors are the CSS classes of spans, see WithHTMLTemplateDefinition.
+	FormatHTML
+	// FormatMarkdown renders the diagnostics for issues and chats with the TemplateDefinitionMarkdown template.
+	FormatMarkdown
+)
+
+// RenderOptions controls how the diagnostics are written to a writer.
+type RenderOptions struct {
+	// Color is used by FormatText only.
+	Color ColorMode
+	// Width is the column at which the messages, notes and helps are wrapped.
+	// 0 detects the width of the terminal, the output is not wrapped if the writer is not a terminal
+	// or the format is not FormatText. Negative values disable the wrapping.
+	Width  int
+	Format Format
+}
+
+// Fprint renders the full diagnostic of err to the writer.
+// Errors that are not *Error are written with their message.
+func Fprint(w io.Writer, err error, opts RenderOptions) (int, error) {
+	return io.WriteString(w, opts.renderer(w).renderDocument(err))
+}
+
+// Sprint renders the full diagnostic of err, the colors are decided by color.NoColor with ColorAuto.
+// The output is wrapped only if the width is set.
+func Sprint(err error, opts RenderOptions) string {
+	return opts.renderer(nil).renderDocument(err)
+}
+
+func (o RenderOptions) renderer(w io.Writer) *renderer {
+	return newRenderer(o.colored(w), false).withWidth(o.width(w)).withFormat(o.Format)
+}
+
+func (o RenderOptions) colored(w io.Writer) bool {
+	switch o.Color {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true
+	}
+
+	if w == nil {
+		return !color.NoColor
+	}
+
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+var htmlTag = regexp.MustCompile("<[^>]*>")
+
+// cycleMarker is rendered instead of an error that wraps itself.
+const cycleMarker = "<cycle>"
+
+// renderer renders an error and its nested errors with the same options.
+type renderer struct {
+	color   bool
+	verbose bool
+	// width is the column at which the text is wrapped, 0 disables the wrapping
+	width  int
+	format Format
+	// path is the chain of the errors being rendered, it is used to detect cycles
+	path []*Error
+	// templates are the copies of the templates bound to the renderer until the outermost error is rendered
+	templates map[*templatePool]*boundTemplate
+}
+
+func newRenderer(color, verbose bool) *renderer {
+	return &renderer{
+		color:   color,
+		verbose: verbose,
+	}
+}
+
+func (r *renderer) withWidth(width int) *renderer {
+	r.width = width
+	return r
+}
+
+func (r *renderer) withFormat(format Format) *renderer {
+	r.format = format
+	return r
+}
+
+func (r *renderer) renderError(err error) string {
+	if e, ok := err.(*Error); ok {
+		return r.render(e)
+	}
+
+	result := err.Error()
+	if r.verbose {
+		result = fmt.Sprintf("%+v", err)
+	}
+
+	return r.escape(result)
+}
+
+// renderSection renders a nested error as a Markdown section, the plain errors get a heading like the *Error ones.
+func (r *renderer) renderSection(err error) string {
+	if _, ok := err.(*Error); ok || r.format != FormatMarkdown {
+		return r.renderError(err)
+	}
+
+	return markdownHeading(len(r.path)+1) + " " + r.renderError(err)
+}
+
+// renderDocument renders the outermost error, the HTML is put into a pre element.
+func (r *renderer) renderDocument(err error) string {
+	if r.format == FormatHTML {
+		return `<pre class="diagnostic">` + r.renderError(err) + `</pre>`
+	}
+
+	return r.renderError(err)
+}
+
+func (r *renderer) render(e *Error) string {
+	if slices.Contains(r.path, e) {
+		return r.escape(cycleMarker)
+	}
+
+	r.path = append(r.path, e)
+	defer func() { r.path = r.path[:len(r.path)-1] }()
+
+	// the copies of the templates are reused by the other renderers after the outermost error
+	if len(r.path) == 1 {
+		defer r.release()
+	}
+
+	var result strings.Builder
+
+	init := e.getInit()
+	data := e.templateData(init, r.verbose)
+
+	// the wrapped errors below the maximum depth are only counted
+	if init.maxDepth > 0 && len(r.path) >= init.maxDepth && len(e.wrapped) > 0 {
+		data[dataWrapped] = nil
+		data[dataTruncated] = countWrapped(e.wrapped, r.path)
+	}
+
+	name := templateNameError
+	if r.format == FormatMarkdown {
+		name = templateNameMarkdown
+	}
+
+	bound := r.bind(init)
+	data[dataIndent] = r.indent(bound, data)
+
+	if r.format == FormatMarkdown {
+		r.markdownData(bound, data)
+	}
+
+	if err := bound.template.ExecuteTemplate(&result, name, data); err != nil {
+		log.Printf("failed to execute error template: %v", err)
+		// fall back to just the error message
+		result.Reset()
+		result.WriteString(r.escape(e.message))
+	}
+
+	return result.String()
+}
+
+// indent returns the width of the rendered message prefix and the separator after it,
+// the message is wrapped to the width of the renderer after them.
+func (r *renderer) indent(bound *boundTemplate, data map[string]any) int {
+	if r.width <= 0 {
+		return 0
+	}
+
+	var prefix strings.Builder
+	if err := bound.template.ExecuteTemplate(&prefix, string(TemplateDefinitionMessagePrefix), data); err != nil {
+		return 0
+	}
+
+	text := prefix.String()
+	if r.format == FormatHTML {
+		text = html.UnescapeString(htmlTag.ReplaceAllString(text, ""))
+	}
+
+	return stringWidth(text) + len(": ")
+}
+
+// markdownData adds the heading of the nested errors and the fence of the source code to the data.
+func (r *renderer) markdownData(bound *boundTemplate, data map[string]any) {
+	data[dataHeading] = ""
+	if depth := len(r.path); depth > 1 {
+		data[dataHeading] = markdownHeading(depth)
+	}
+
+	var code strings.Builder
+
+	for _, name := range []TemplateDefinition{TemplateDefinitionSnippets, TemplateDefinitionSuggestions, TemplateDefinitionStack} {
+		if err := bound.template.ExecuteTemplate(&code, string(name), data); err != nil {
+			log.Printf("failed to execute error template: %v", err)
+		}
+	}
+
+	data[dataFence] = fence(code.String())
+}
+
+// escape escapes the text for the format of the renderer.
+func (r *renderer) escape(text string) string {
+	switch r.format {
+	case FormatHTML:
+		return html.EscapeString(text)
+	case FormatMarkdown:
+		return markdownEscape(text)
+	default:
+		return text
+	}
+}
+
+// bind returns the copy of the template of the initializer that renders the nested errors with this renderer,
+// the colored template is used for colored text only.
+func (r *renderer) bind(init *Init) *boundTemplate {
+	pool := init.plainTemplates
+
+	switch {
+	case r.format == FormatHTML:
+		pool = init.htmlTemplates
+	case r.format == FormatText && r.color:
+		pool = init.colorTemplates
+	}
+
+	if result, ok := r.templates[pool]; ok {
+		return result
+	}
+
+	if r.templates == nil {
+		r.templates = make(map[*templatePool]*boundTemplate)
+	}
+
+	result := pool.Get().(*boundTemplate)
+	result.renderer = r
+	r.templates[pool] = result
+
+	return result
+}
+
+// release returns the copies of the templates to their pools.
+func (r *renderer) release() {
+	for pool, bound := range r.templates {
+		bound.renderer = nil
+		pool.Put(bound)
+	}
+
+	clear(r.templates)
+}
+
+// wrap splits the text into lines that fit in the width of the renderer after the indentation.
+func (r *renderer) wrap(indent int, text string) []string {
+	return wrapText(text, indent, r.width)
+}
+
+// templatePool has the copies of a template whose render and wrap functions call the renderer they are bound to,
+// so the template is parsed once per initializer and copied only when every copy is in use.
+type templatePool struct {
+	sync.Pool
+}
+
+// boundTemplate is a copy of the template of a pool, it is bound to a renderer while it renders an error.
+type boundTemplate struct {
+	// renderer is an interface so that the package variables are not initialized in a cycle
+	renderer interface {
+		renderError(err error) string
+		renderSection(err error) string
+		wrap(indent int, text string) []string
+	}
+	template interface {
+		ExecuteTemplate(w io.Writer, name string, data any) error
+	}
+}
+
+func newTextTemplatePool(base *template.Template) *templatePool {
+	result := &templatePool{}
+	result.New = func() any {
+		bound := &boundTemplate{}
+		bound.template = template.Must(base.Clone()).Funcs(template.FuncMap{
+			funcRender:  func(err error) string { return bound.renderer.renderError(err) },
+			funcWrap:    func(indent int, text string) []string { return bound.renderer.wrap(indent, text) },
+			funcSection: func(err error) string { return bound.renderer.renderSection(err) },
+		})
+
+		return bound
+	}
+
+	return result
+}
+
+func newHTMLTemplatePool(base *htmltemplate.Template) *templatePool {
+	result := &templatePool{}
+	result.New = func() any {
+		bound := &boundTemplate{}
+		bound.template = htmltemplate.Must(base.Clone()).Funcs(htmltemplate.FuncMap{
+			funcRender: func(err error) htmltemplate.HTML { return htmltemplate.HTML(bound.renderer.renderError(err)) },
+			funcWrap:   func(indent int, text string) []string { return bound.renderer.wrap(indent, text) },
+		})
+
+		return bound
+	}
+
+	return result
+}
+
+// templateData returns the data of the error templates.
+func (e *Error) templateData(init *Init, verbose bool) map[string]any {
+	helps := e.helps
+	if help := init.registry.explainHelp(e.code); help != "" {
+		helps = append(slices.Clip(helps), help)
+	}
+
+	snippets := e.snippetViews(len(e.notes) > 0 || len(helps) > 0 || len(e.suggestions) > 0)
+
+	// the gutter of the suggestions is aligned with the snippets
+	width := 2
+	if len(snippets) > 0 {
+		width = len(snippets[0].Indent)
+	}
+
+	data := map[string]any{
+		dataMessage:     e.message,
+		dataCause:       e.cause,
+		dataWrapped:     e.wrapped,
+		dataCode:        e.code,
+		dataSeverity:    e.severity,
+		dataNotes:       e.notes,
+		dataHelps:       helps,
+		dataSnippets:    snippets,
+		dataSuggestions: e.suggestionViews(width),
+	}
+
+	if verbose {
+		data[dataStack] = e.stack
+	}
+
+	if e.parameters != nil {
+		data[dataParameters] = e.parameters
+	}
+
+	if len(e.additionalTemplateData) > 0 {
+		maps.Copy(data, e.additionalTemplateData)
+	}
+
+	return data
+}
+
+// countWrapped returns the number of the wrapped errors in the tree, the errors of the path are not followed.
+func countWrapped(wrapped []error, path []*Error) int {
+	result := 0
+
+	for _, err := range wrapped {
+		if err == nil {
+			continue
+		}
+
+		result++
+
+		if e, ok := err.(*Error); ok && !slices.Contains(path, e) {
+			result += countWrapped(e.wrapped, append(slices.Clip(path), e))
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,270 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Fprint(t *testing.T) {
+	original := color.NoColor
+	defer func() { color.NoColor = original }()
+
+	err := New("test").
+		Code(1).
+		Note("note").
+		Wrap(New("wrapped").Help("help"))
+
+	const plain = `error[E0001]: test
+   = note: note
+
+error: wrapped
+   = help: help`
+
+	for _, tt := range []struct {
+		name    string
+		noColor bool
+		env     map[string]string
+		opts    RenderOptions
+		colored bool
+	}{
+		{
+			name:    "auto does not color a buffer",
+			noColor: false,
+			opts:    RenderOptions{Color: ColorAuto},
+			colored: false,
+		},
+		{
+			name:    "always colors even if colors are disabled globally",
+			noColor: true,
+			opts:    RenderOptions{Color: ColorAlways},
+			colored: true,
+		},
+		{
+			name:    "never",
+			noColor: false,
+			opts:    RenderOptions{Color: ColorNever},
+			colored: false,
+		},
+		{
+			name:    "CLICOLOR_FORCE colors a buffer",
+			noColor: true,
+			env:     map[string]string{"CLICOLOR_FORCE": "1"},
+			opts:    RenderOptions{Color: ColorAuto},
+			colored: true,
+		},
+		{
+			name:    "NO_COLOR wins over CLICOLOR_FORCE",
+			noColor: false,
+			env:     map[string]string{"CLICOLOR_FORCE": "1", "NO_COLOR": "1"},
+			opts:    RenderOptions{Color: ColorAuto},
+			colored: false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			color.NoColor = tt.noColor
+
+			t.Setenv("NO_COLOR", "")
+			t.Setenv("CLICOLOR_FORCE", "")
+
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			var buf bytes.Buffer
+
+			n, writeErr := Fprint(&buf, err, tt.opts)
+			require.NoError(t, writeErr)
+			assert.Equal(t, buf.Len(), n)
+
+			if tt.colored {
+				assert.Contains(t, buf.String(), "\x1b[")
+				// the nested errors are rendered with the same options
+				assert.Contains(t, buf.String(), "\x1b[31;1merror\x1b[0;22m\x1b[1m: wrapped")
+				assert.Equal(t, plain, ansiEscape.ReplaceAllString(buf.String(), ""))
+			} else {
+				assert.Equal(t, plain, buf.String())
+			}
+		})
+	}
+}
+
+func Test_Sprint(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	assert.Equal(t, "error: test", Sprint(New("test"), RenderOptions{}))
+	assert.Contains(t, Sprint(New("test"), RenderOptions{Color: ColorAlways}), "\x1b[")
+}
+
+func Test_Render_Cycle(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	self := New("self")
+	self.Wrap(self)
+
+	first := New("first")
+	second := New("second").Wrap(first)
+	first.Cause(second)
+
+	for _, tt := range []struct {
+		name     string
+		err      *Error
+		expected string
+		compact  string
+		dump     string
+		visited  int
+	}{
+		{
+			name:     "an error wrapping itself",
+			err:      self,
+			expected: "error: self\n\n<cycle>",
+			compact:  "self: <cycle>",
+			dump:     `&errors.Error{message:"self", wrapped:[]error{<cycle>}}`,
+			visited:  1,
+		},
+		{
+			name:     "cycle through the cause",
+			err:      first,
+			expected: "error: first\n  --> error: second\n   | \n   | <cycle>",
+			compact:  "first: second: <cycle>",
+			dump:     `&errors.Error{message:"first", cause:&errors.Error{message:"second", wrapped:[]error{<cycle>}}}`,
+			visited:  2,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.err.Error())
+			assert.Equal(t, tt.compact, fmt.Sprint(tt.err))
+			assert.Equal(t, tt.dump, fmt.Sprintf("%#v", tt.err))
+
+			visited := 0
+			Walk(tt.err, func(*Error) bool {
+				visited++
+				return true
+			})
+			assert.Equal(t, tt.visited, visited)
+
+			data, err := tt.err.MarshalJSON()
+			require.NoError(t, err)
+			assert.Contains(t, string(data), `"message":"\u003ccycle\u003e"`)
+
+			assert.Contains(t, tt.err.LogValue().String(), "<cycle>")
+		})
+	}
+}
+
+func Test_Render_Max_Depth(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	init := NewInitializer(WithMaxDepth(2))
+
+	err := init.NewError("first").
+		Wrap(init.NewError("second").
+			Wrap(init.NewError("third").Wrap(fmt.Errorf("fourth"))).
+			Wrap(fmt.Errorf("fifth")))
+
+	assert.Equal(t, `error: first
+
+error: second
+
+... 3 more errors`, err.Error())
+
+	SetMaxDepth(1)
+	defer Reset()
+
+	assert.Equal(t, "error: first\n\n... 1 more error", New("first").Wrap(New("second")).Error())
+}
+
+func Test_Render_Tree_Layout(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	init := NewInitializer(WithLayout(LayoutTree))
+
+	for _, tt := range []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name: "nested",
+			err: init.NewError("first").
+				Note("first note").
+				Wrap(init.NewError("second").
+					Help("second help").
+					Wrap(init.NewError("third"))).
+				Wrap(fmt.Errorf("fourth")),
+			expected: `error: first
+   = note: first note
+├─▶ error: second
+│      = help: second help
+│   ╰─▶ error: third
+╰─▶ fourth`,
+		},
+		{
+			name: "siblings",
+			err: init.NewError("first").
+				Wrap(init.NewError("second")).
+				Wrap(init.NewError("third")),
+			expected: `error: first
+├─▶ error: second
+╰─▶ error: third`,
+		},
+		{
+			name: "truncated",
+			err: NewInitializer(WithLayout(LayoutTree), WithMaxDepth(1)).NewError("first").
+				Wrap(init.NewError("second")),
+			expected: `error: first
+╰─▶ ... 1 more error`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.err.Error())
+		})
+	}
+
+	SetLayout(LayoutTree)
+	defer Reset()
+
+	assert.Equal(t, "error: first\n╰─▶ error: second", New("first").Wrap(New("second")).Error())
+}
+
+func Test_Render_Keeps_Escape_Sequences_Of_The_Content(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	err := New("a\x1b[31mred\x1b[0m").Wrap(New("b\x1b[1mbold"))
+
+	assert.Equal(t, "error: a\x1b[31mred\x1b[0m\n\nerror: b\x1b[1mbold", err.Error())
+	assert.Equal(t, "**error: b\x1b\\[1mbold**", Sprint(New("b\x1b[1mbold"), RenderOptions{Format: FormatMarkdown}))
+}
+
+func Test_Render_Functions(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	// the functions documented before the internal functions of the templates were added
+	init := NewInitializer(WithFunctions(template.FuncMap{
+		"bold":    func(format string, a ...any) string { return strings.ToUpper(fmt.Sprintf(format, a...)) },
+		"boldRed": fmt.Sprintf,
+	}))
+
+	err := init.NewError("test").Cause(init.NewError("cause").Note("note"))
+
+	assert.Equal(t, "error: TEST\n  --> error: CAUSE\n   |    = NOTE: note", err.Error())
+	assert.Contains(t, Sprint(err, RenderOptions{Format: FormatHTML}), `<span class="message">: test</span>`)
+}
@@ -0,0 +1,350 @@
+// Package sarif exports diagnostics in the SARIF 2.1.0 format that is consumed by code scanning tools.
+package sarif
+
+import (
+	"encoding/json"
+	goerrors "errors"
+	"io"
+	"unicode/utf8"
+
+	"github.com/bsido/go-errors/errors"
+)
+
+const (
+	Version = "2.1.0"
+	Schema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// Log is the root object of a SARIF file.
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+type Run struct {
+	Tool       Tool     `json:"tool"`
+	ColumnKind string   `json:"columnKind"`
+	Results    []Result `json:"results"`
+}
+
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+type Driver struct {
+	Name           string `json:"name"`
+	Version        string `json:"version,omitempty"`
+	InformationURI string `json:"informationUri,omitempty"`
+	Rules          []Rule `json:"rules,omitempty"`
+}
+
+// Rule is the metadata of an error code.
+type Rule struct {
+	ID               string   `json:"id"`
+	ShortDescription *Message `json:"shortDescription,omitempty"`
+	FullDescription  *Message `json:"fullDescription,omitempty"`
+	Help             *Message `json:"help,omitempty"`
+}
+
+type Message struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+// Result is a diagnostic.
+type Result struct {
+	RuleID           string     `json:"ruleId,omitempty"`
+	RuleIndex        *int       `json:"ruleIndex,omitempty"`
+	Level            string     `json:"level"`
+	Message          Message    `json:"message"`
+	Locations        []Location `json:"locations,omitempty"`
+	RelatedLocations []Location `json:"relatedLocations,omitempty"`
+	Fixes            []Fix      `json:"fixes,omitempty"`
+}
+
+// Location is a region of a file, the related locations of the notes and helps have a message only.
+type Location struct {
+	PhysicalLocation *PhysicalLocation `json:"physicalLocation,omitempty"`
+	Message          *Message          `json:"message,omitempty"`
+}
+
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region,omitempty"`
+}
+
+type ArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// Region is 1-based, the end column is exclusive.
+type Region struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+}
+
+// Fix is a suggestion of an error.
+type Fix struct {
+	Description     *Message         `json:"description,omitempty"`
+	ArtifactChanges []ArtifactChange `json:"artifactChanges"`
+}
+
+type ArtifactChange struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Replacements     []Replacement    `json:"replacements"`
+}
+
+type Replacement struct {
+	DeletedRegion   Region           `json:"deletedRegion"`
+	InsertedContent *ArtifactContent `json:"insertedContent,omitempty"`
+}
+
+type ArtifactContent struct {
+	Text string `json:"text"`
+}
+
+type Option func(*options)
+
+type options struct {
+	version        string
+	informationURI string
+	registry       *errors.Registry
+}
+
+func WithToolVersion(version string) Option {
+	return func(opts *options) {
+		opts.version = version
+	}
+}
+
+func WithInformationURI(uri string) Option {
+	return func(opts *options) {
+		opts.informationURI = uri
+	}
+}
+
+// WithRegistry adds the definitions of the registered error codes to the rules.
+func WithRegistry(registry *errors.Registry) Option {
+	return func(opts *options) {
+		opts.registry = registry
+	}
+}
+
+// Convert returns a SARIF log with a single run of the tool.
+// Every *Error in the trees of the diagnostics is a result, the error codes are the rules of the results.
+// Diagnostics that are not *Error are results with their messages.
+func Convert(tool string, diagnostics []error, opts ...Option) *Log {
+	options := &options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	c := &converter{
+		options: options,
+		rules:   make(map[string]int),
+		// the results are required even if there are no diagnostics
+		results: make([]Result, 0),
+	}
+
+	for _, diagnostic := range diagnostics {
+		c.convert(diagnostic)
+	}
+
+	return &Log{
+		Schema:  Schema,
+		Version: Version,
+		Runs: []Run{
+			{
+				Tool: Tool{
+					Driver: Driver{
+						Name:           tool,
+						Version:        options.version,
+						InformationURI: options.informationURI,
+						Rules:          c.driverRules,
+					},
+				},
+				// the columns of the labels are counted in characters
+				ColumnKind: "unicodeCodePoints",
+				Results:    c.results,
+			},
+		},
+	}
+}
+
+// Write writes the SARIF log of the diagnostics as indented JSON.
+func Write(w io.Writer, tool string, diagnostics []error, opts ...Option) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(Convert(tool, diagnostics, opts...))
+}
+
+type converter struct {
+	options     *options
+	rules       map[string]int
+	driverRules []Rule
+	results     []Result
+}
+
+func (c *converter) convert(diagnostic error) {
+	if diagnostic == nil {
+		return
+	}
+
+	var e *errors.Error
+	if !goerrors.As(diagnostic, &e) {
+		c.results = append(c.results, Result{
+			Level:   level(errors.SeverityError),
+			Message: Message{Text: diagnostic.Error()},
+		})
+
+		return
+	}
+
+	errors.Walk(diagnostic, func(e *errors.Error) bool {
+		c.results = append(c.results, c.result(e))
+		return true
+	})
+}
+
+func (c *converter) result(e *errors.Error) Result {
+	result := Result{
+		RuleID:  e.GetCode(),
+		Level:   level(e.Severity()),
+		Message: Message{Text: e.Summary()},
+	}
+
+	if code := e.GetCode(); code != "" {
+		index := c.rule(code)
+		result.RuleIndex = &index
+	}
+
+	sources := errors.SourceLines(e.Snippets())
+
+	for _, label := range e.Labels() {
+		location := Location{
+			PhysicalLocation: &PhysicalLocation{
+				ArtifactLocation: ArtifactLocation{URI: label.File},
+				Region:           region(sources[label.File], label.Line, label.Column, max(label.Span, 1)),
+			},
+		}
+
+		if label.Text != "" {
+			location.Message = &Message{Text: label.Text}
+		}
+
+		if label.Primary {
+			result.Locations = append(result.Locations, location)
+			continue
+		}
+
+		result.RelatedLocations = append(result.RelatedLocations, location)
+	}
+
+	for _, note := range e.GetNotes() {
+		result.RelatedLocations = append(result.RelatedLocations, Location{Message: &Message{Text: "note: " + note}})
+	}
+
+	for _, help := range e.GetHelps() {
+		result.RelatedLocations = append(result.RelatedLocations, Location{Message: &Message{Text: "help: " + help}})
+	}
+
+	for _, suggestion := range e.Suggestions() {
+		// suggestions with a negative span do not replace a region
+		if suggestion.Span < 0 {
+			continue
+		}
+
+		replacement := Replacement{
+			DeletedRegion: *region(sources[suggestion.File], suggestion.Line, suggestion.Column, suggestion.Span),
+		}
+
+		if suggestion.Replacement != "" {
+			replacement.InsertedContent = &ArtifactContent{Text: suggestion.Replacement}
+		}
+
+		result.Fixes = append(result.Fixes, Fix{
+			Description: &Message{Text: suggestion.Message},
+			ArtifactChanges: []ArtifactChange{
+				{
+					ArtifactLocation: ArtifactLocation{URI: suggestion.File},
+					Replacements:     []Replacement{replacement},
+				},
+			},
+		})
+	}
+
+	return result
+}
+
+// rule returns the index of the rule of the code, the rule is added when the code is first seen.
+func (c *converter) rule(code string) int {
+	if index, ok := c.rules[code]; ok {
+		return index
+	}
+
+	rule := Rule{ID: code}
+
+	if c.options.registry != nil {
+		if definition, ok := c.options.registry.Lookup(code); ok {
+			rule.ShortDescription = &Message{Text: definition.Title}
+
+			if definition.Explanation != "" {
+				rule.FullDescription = &Message{Text: definition.Explanation}
+			}
+
+			if explanation, err := c.options.registry.Explain(code); err == nil {
+				rule.Help = &Message{Text: explanation, Markdown: explanation}
+			}
+		}
+	}
+
+	c.rules[code] = len(c.driverRules)
+	c.driverRules = append(c.driverRules, rule)
+
+	return c.rules[code]
+}
+
+func level(severity errors.Severity) string {
+	switch severity {
+	case errors.SeverityWarning:
+		return "warning"
+	case errors.SeverityNote, errors.SeverityInfo:
+		return "note"
+	default:
+		return "error"
+	}
+}
+
+// region calculates where a span of characters ends, the line break at the end of a line counts as one character.
+// Without the source or after the end of the line the span is assumed to end on its line.
+func region(source map[int]string, line, column, span int) *Region {
+	result := &Region{
+		StartLine:   line,
+		StartColumn: column,
+		EndLine:     line,
+		EndColumn:   column + span,
+	}
+
+	for remaining := span; ; {
+		text, ok := source[result.EndLine]
+		if !ok {
+			result.EndColumn = column + remaining
+			return result
+		}
+
+		// the characters until the end of the line and the line break
+		length := utf8.RuneCountInString(text) - column + 2
+		if remaining < length || length <= 0 {
+			result.EndColumn = column + remaining
+			return result
+		}
+
+		remaining -= length
+		result.EndLine++
+		column = 1
+	}
+}
@@ -0,0 +1,284 @@
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/bsido/go-errors/errors"
+	"github.com/bsido/go-errors/warnings"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const source = `fn main() {
+    for foo in Foo {}
+}`
+
+func Test_Convert(t *testing.T) {
+	registry := errors.NewRegistry("")
+	registry.Register("E0277", errors.CodeDefinition{
+		Title:       "the trait bound is not satisfied",
+		Explanation: "You tried to use a type which doesn't implement some trait in a place which expected that trait.",
+	})
+
+	err := errors.New("'Foo' is not an iterator").
+		CodeString("E0277").
+		Snippet("src/main.rs", 1, source).
+		Label("src/main.rs", 2, 16, 3, "'Foo' is not an iterator").
+		SecondaryLabel("src/main.rs", 1, 1, 17, "in this function").
+		Note("maybe try calling '.iter()' or a similar method").
+		Help("the trait 'std::iter::Iterator' is not implemented for 'Foo'").
+		SuggestWithApplicability(errors.Label{File: "src/main.rs", Line: 2, Column: 19, Text: "call '.iter()'"},
+			"", ".iter()", errors.ApplicabilityMachineApplicable)
+
+	data, marshalErr := json.Marshal(Convert("rustc", []error{err},
+		WithToolVersion("1.80.0"), WithInformationURI("https://www.rust-lang.org"), WithRegistry(registry)))
+	require.NoError(t, marshalErr)
+
+	assert.JSONEq(t, `{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": [{
+			"tool": {
+				"driver": {
+					"name": "rustc",
+					"version": "1.80.0",
+					"informationUri": "https://www.rust-lang.org",
+					"rules": [{
+						"id": "E0277",
+						"shortDescription": {"text": "the trait bound is not satisfied"},
+						"fullDescription": {"text": "You tried to use a type which doesn't implement some trait in a place which expected that trait."},
+						"help": {
+							"text": "# E0277: the trait bound is not satisfied\n\nYou tried to use a type which doesn't implement some trait in a place which expected that trait.\n",
+							"markdown": "# E0277: the trait bound is not satisfied\n\nYou tried to use a type which doesn't implement some trait in a place which expected that trait.\n"
+						}
+					}]
+				}
+			},
+			"columnKind": "unicodeCodePoints",
+			"results": [{
+				"ruleId": "E0277",
+				"ruleIndex": 0,
+				"level": "error",
+				"message": {"text": "'Foo' is not an iterator"},
+				"locations": [{
+					"physicalLocation": {
+						"artifactLocation": {"uri": "src/main.rs"},
+						"region": {"startLine": 2, "startColumn": 16, "endLine": 2, "endColumn": 19}
+					},
+					"message": {"text": "'Foo' is not an iterator"}
+				}],
+				"relatedLocations": [
+					{
+						"physicalLocation": {
+							"artifactLocation": {"uri": "src/main.rs"},
+							"region": {"startLine": 1, "startColumn": 1, "endLine": 2, "endColumn": 6}
+						},
+						"message": {"text": "in this function"}
+					},
+					{"message": {"text": "note: maybe try calling '.iter()' or a similar method"}},
+					{"message": {"text": "help: the trait 'std::iter::Iterator' is not implemented for 'Foo'"}}
+				],
+				"fixes": [{
+					"description": {"text": "call '.iter()'"},
+					"artifactChanges": [{
+						"artifactLocation": {"uri": "src/main.rs"},
+						"replacements": [{
+							"deletedRegion": {"startLine": 2, "startColumn": 19, "endLine": 2, "endColumn": 19},
+							"insertedContent": {"text": ".iter()"}
+						}]
+					}]
+				}]
+			}]
+		}]
+	}`, string(data))
+}
+
+func Test_Convert_Results(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		errs     []error
+		expected []string
+		rules    []string
+	}{
+		{
+			name:     "plain errors",
+			errs:     []error{goerrors.New("plain"), nil},
+			expected: []string{"error: plain"},
+		},
+		{
+			name:     "plain causes are part of the message",
+			errs:     []error{errors.New("failed to read").Cause(fmt.Errorf("permission denied"))},
+			expected: []string{"error: failed to read: permission denied"},
+		},
+		{
+			name: "every error of the tree is a result",
+			errs: []error{
+				errors.New("build failed").
+					Wrap(warnings.New("unused variable").Code(1)).
+					Cause(errors.New("note").SetSeverity(errors.SeverityNote)),
+			},
+			expected: []string{"error: build failed", "note: note", "warning[W0001]: unused variable"},
+			rules:    []string{"W0001"},
+		},
+		{
+			name: "the rules are not repeated",
+			errs: []error{
+				errors.New("first").CodeString("E0001"),
+				errors.New("second").CodeString("E0002"),
+				errors.New("third").CodeString("E0001"),
+			},
+			expected: []string{"error[E0001]: first", "error[E0002]: second", "error[E0001]: third"},
+			rules:    []string{"E0001", "E0002"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			run := Convert("test", tt.errs).Runs[0]
+
+			var results []string
+			for _, result := range run.Results {
+				code := ""
+				if result.RuleIndex != nil {
+					code = "[" + run.Tool.Driver.Rules[*result.RuleIndex].ID + "]"
+				}
+
+				results = append(results, result.Level+code+": "+result.Message.Text)
+			}
+
+			var rules []string
+			for _, rule := range run.Tool.Driver.Rules {
+				rules = append(rules, rule.ID)
+			}
+
+			assert.Equal(t, tt.expected, results)
+			assert.Equal(t, tt.rules, rules)
+		})
+	}
+}
+
+func Test_Region(t *testing.T) {
+	src := map[int]string{1: "ab", 2: "cdé", 3: "f"}
+
+	for _, tt := range []struct {
+		name     string
+		source   map[int]string
+		line     int
+		column   int
+		span     int
+		expected Region
+	}{
+		{
+			name:     "single line",
+			source:   src,
+			line:     2,
+			column:   2,
+			span:     2,
+			expected: Region{StartLine: 2, StartColumn: 2, EndLine: 2, EndColumn: 4},
+		},
+		{
+			name:     "multiple lines",
+			source:   src,
+			line:     1,
+			column:   2,
+			span:     6,
+			expected: Region{StartLine: 1, StartColumn: 2, EndLine: 3, EndColumn: 1},
+		},
+		{
+			name:     "until the end of the line",
+			source:   src,
+			line:     1,
+			column:   1,
+			span:     2,
+			expected: Region{StartLine: 1, StartColumn: 1, EndLine: 1, EndColumn: 3},
+		},
+		{
+			name:     "after the end of the line",
+			source:   src,
+			line:     1,
+			column:   5,
+			span:     3,
+			expected: Region{StartLine: 1, StartColumn: 5, EndLine: 1, EndColumn: 8},
+		},
+		{
+			name:     "without source",
+			line:     1,
+			column:   2,
+			span:     6,
+			expected: Region{StartLine: 1, StartColumn: 2, EndLine: 1, EndColumn: 8},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, *region(tt.source, tt.line, tt.column, tt.span))
+		})
+	}
+}
+
+func Test_Write_Schema(t *testing.T) {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft4
+
+	schema, compileErr := compiler.Compile("testdata/sarif-schema-2.1.0.json")
+	require.NoError(t, compileErr)
+
+	registry := errors.NewRegistry("")
+	registry.Register("E0277", errors.CodeDefinition{Title: "the trait bound is not satisfied"})
+
+	for _, tt := range []struct {
+		name string
+		errs []error
+	}{
+		{
+			name: "no diagnostics",
+		},
+		{
+			name: "diagnostics",
+			errs: []error{
+				goerrors.New("plain"),
+				errors.New("'Foo' is not an iterator").
+					CodeString("E0277").
+					Snippet("src/main.rs", 1, source).
+					Label("src/main.rs", 2, 16, 3, "").
+					SecondaryLabel("src/main.rs", 1, 1, 17, "in this function").
+					Note("note").
+					Help("help").
+					Suggest(errors.Label{File: "src/main.rs", Line: 2, Column: 16}, "Foo", "Bar").
+					Suggest(errors.Label{File: "src/main.rs", Line: 2, Column: 5}, "for ", "").
+					Suggest(errors.Label{File: "src/main.rs", Line: 2, Column: 1, Span: -1}, "", "Bar").
+					Wrap(warnings.New("unused variable").Code(1).Label("src/lib.rs", 10, 5, 0, "")),
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Write(&buf, "test", tt.errs, WithToolVersion("1.0.0"), WithRegistry(registry)))
+
+			var document any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &document))
+
+			assert.NoError(t, schema.Validate(document))
+		})
+	}
+
+	t.Run("invalid log", func(t *testing.T) {
+		log := Convert("test", []error{goerrors.New("plain")})
+		log.Runs[0].Results[0].Level = "fatal"
+		log.Runs[0].Results[0].Locations = []Location{{PhysicalLocation: &PhysicalLocation{Region: &Region{}}}}
+
+		data, marshalErr := json.Marshal(log)
+		require.NoError(t, marshalErr)
+
+		var document any
+		require.NoError(t, json.Unmarshal(data, &document))
+
+		var validationErr *jsonschema.ValidationError
+		require.ErrorAs(t, schema.Validate(document), &validationErr)
+
+		violations := fmt.Sprintf("%#v", validationErr)
+		assert.Contains(t, violations, "[I#/runs/0/results/0/level]")
+		assert.Contains(t, violations, "[I#/runs/0/results/0/locations/0/physicalLocation/region/startLine]")
+	})
+}
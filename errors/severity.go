@@ -0,0 +1,93 @@
+package errors
+
+import (
+	"fmt"
+)
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityNote
+	SeverityInfo
+	// SeverityBug is an internal error of the program, not a problem of its input.
+	SeverityBug
+)
+
+var severityNames = map[Severity]string{
+	SeverityError:   "error",
+	SeverityWarning: "warning",
+	SeverityNote:    "note",
+	SeverityInfo:    "info",
+	SeverityBug:     "error: internal compiler error",
+}
+
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	if _, ok := severityNames[s]; !ok {
+		return nil, fmt.Errorf("unknown severity: %d", int(s))
+	}
+
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	for severity, name := range severityNames {
+		if name == string(text) {
+			*s = severity
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown severity: %q", text)
+}
+
+// isBug reports whether the severity is SeverityBug, the code of a bug is not rendered.
+func isBug(severity Severity) bool {
+	return severity == SeverityBug
+}
+
+// SetSeverity changes the severity of the error, it is SeverityError by default.
+func (e *Error) SetSeverity(severity Severity) *Error {
+	e = e.mutable()
+	e.severity = severity
+
+	return e
+}
+
+func (e *Error) Severity() Severity {
+	return e.severity
+}
+
+// IsSeverity reports whether any *Error in the tree of err has the given severity.
+func IsSeverity(err error, severity Severity) bool {
+	found := false
+
+	Walk(err, func(e *Error) bool {
+		found = e.severity == severity
+		return !found
+	})
+
+	return found
+}
+
+func (p palette) severityColor(severity Severity, text string) string {
+	switch severity {
+	case SeverityWarning:
+		return p.boldYellow(text)
+	case SeverityNote:
+		return p.boldGreen(text)
+	case SeverityInfo:
+		return p.boldCyan(text)
+	default:
+		return p.boldRed(text)
+	}
+}
@@ -0,0 +1,166 @@
+package errors
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// LogValue implements slog.LogValuer, the error is logged as a group of its fields.
+// A nil error is logged as "<nil>".
+func (e *Error) LogValue() slog.Value {
+	return e.logValue(nil)
+}
+
+// logValue resolves the nested errors as well, path is the chain of the errors being resolved.
+func (e *Error) logValue(path []*Error) slog.Value {
+	if e == nil {
+		return slog.StringValue("<nil>")
+	}
+
+	if slices.Contains(path, e) {
+		return slog.StringValue(cycleMarker)
+	}
+
+	path = append(slices.Clip(path), e)
+
+	attrs := []slog.Attr{
+		slog.String("message", e.message),
+		slog.String("severity", e.severity.String()),
+	}
+
+	if e.code != "" {
+		attrs = append(attrs, slog.String("code", e.code))
+	}
+
+	if e.cause != nil {
+		attrs = append(attrs, errorAttr("cause", e.cause, path))
+	}
+
+	if len(e.notes) > 0 {
+		attrs = append(attrs, slog.Any("notes", e.notes))
+	}
+
+	if len(e.helps) > 0 {
+		attrs = append(attrs, slog.Any("helps", e.helps))
+	}
+
+	if len(e.wrapped) > 0 {
+		wrapped := make([]slog.Attr, 0, len(e.wrapped))
+		for i, err := range e.wrapped {
+			wrapped = append(wrapped, errorAttr(strconv.Itoa(i), err, path))
+		}
+
+		attrs = append(attrs, slog.Attr{Key: "wrapped", Value: slog.GroupValue(wrapped...)})
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+func errorAttr(key string, err error, path []*Error) slog.Attr {
+	if e, ok := err.(*Error); ok {
+		return slog.Attr{Key: key, Value: e.logValue(path)}
+	}
+
+	return slog.String(key, err.Error())
+}
+
+// DiagnosticHandler is a slog.Handler for console output.
+// It passes the records to the next handler unchanged, so the *Error attributes are logged by their LogValue,
+// and renders the full diagnostics of the errors to its writer after the record,
+// they are colored if the writer is a terminal. The errors of the attributes added by WithAttrs
+// and the errors in groups are rendered as well.
+type DiagnosticHandler struct {
+	next slog.Handler
+	w    io.Writer
+	mu   *sync.Mutex
+	// diagnostics are the errors of the attributes added by WithAttrs
+	diagnostics []*Error
+}
+
+// NewDiagnosticHandler creates a handler, usually the next handler writes to the same writer:
+//
+//	slog.New(errors.NewDiagnosticHandler(os.Stderr, slog.NewTextHandler(os.Stderr, nil)))
+func NewDiagnosticHandler(w io.Writer, next slog.Handler) *DiagnosticHandler {
+	return &DiagnosticHandler{
+		next: next,
+		w:    w,
+		mu:   &sync.Mutex{},
+	}
+}
+
+func (h *DiagnosticHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *DiagnosticHandler) Handle(ctx context.Context, record slog.Record) error {
+	diagnostics := slices.Clip(h.diagnostics)
+
+	record.Attrs(func(attr slog.Attr) bool {
+		diagnostics = appendDiagnostics(diagnostics, attr)
+		return true
+	})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.next.Handle(ctx, record); err != nil {
+		return err
+	}
+
+	for _, diagnostic := range diagnostics {
+		if _, err := Fprint(h.w, diagnostic, RenderOptions{}); err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(h.w, "\n\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *DiagnosticHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	diagnostics := slices.Clip(h.diagnostics)
+	for _, attr := range attrs {
+		diagnostics = appendDiagnostics(diagnostics, attr)
+	}
+
+	return &DiagnosticHandler{
+		next:        h.next.WithAttrs(attrs),
+		w:           h.w,
+		mu:          h.mu,
+		diagnostics: diagnostics,
+	}
+}
+
+func (h *DiagnosticHandler) WithGroup(name string) slog.Handler {
+	return &DiagnosticHandler{
+		next:        h.next.WithGroup(name),
+		w:           h.w,
+		mu:          h.mu,
+		diagnostics: h.diagnostics,
+	}
+}
+
+// appendDiagnostics appends the *Error of the attribute or the errors in the attributes of its group.
+func appendDiagnostics(diagnostics []*Error, attr slog.Attr) []*Error {
+	if attr.Value.Kind() == slog.KindGroup {
+		for _, attr := range attr.Value.Group() {
+			diagnostics = appendDiagnostics(diagnostics, attr)
+		}
+
+		return diagnostics
+	}
+
+	var e *Error
+	if err, ok := attr.Value.Any().(error); ok && As(err, &e) && e != nil {
+		diagnostics = append(diagnostics, e)
+	}
+
+	return diagnostics
+}
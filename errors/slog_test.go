@@ -0,0 +1,119 @@
+package errors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func removeTime(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Key == slog.TimeKey && len(groups) == 0 {
+		return slog.Attr{}
+	}
+
+	return attr
+}
+
+func Test_LogValue(t *testing.T) {
+	var out bytes.Buffer
+
+	logger := slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{ReplaceAttr: removeTime}))
+
+	logger.Error("failed", "err", New("test").
+		Code(1).
+		Cause(io.EOF).
+		Note("note").
+		Help("help").
+		Wrap(New("wrapped").SetSeverity(SeverityWarning).Cause(New("cause"))).
+		Wrap(io.ErrUnexpectedEOF))
+
+	assert.JSONEq(t, `{
+		"level": "ERROR",
+		"msg": "failed",
+		"err": {
+			"message": "test",
+			"severity": "error",
+			"code": "E0001",
+			"cause": "EOF",
+			"notes": ["note"],
+			"helps": ["help"],
+			"wrapped": {
+				"0": {
+					"message": "wrapped",
+					"severity": "warning",
+					"cause": {"message": "cause", "severity": "error"}
+				},
+				"1": "unexpected EOF"
+			}
+		}
+	}`, out.String())
+}
+
+func Test_DiagnosticHandler(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	var out bytes.Buffer
+
+	logger := slog.New(NewDiagnosticHandler(&out, slog.NewTextHandler(&out, &slog.HandlerOptions{ReplaceAttr: removeTime}))).
+		With("component", "test")
+
+	logger.Info("no errors", "count", 1)
+	logger.Error("failed",
+		"err", New("test").Cause(io.EOF).Help("help"),
+		"wrapped", fmt.Errorf("wrapped: %w", New("other")),
+		"plain", io.EOF)
+
+	assert.Equal(t, `level=INFO msg="no errors" component=test count=1
+level=ERROR msg=failed component=test err.message=test err.severity=error err.cause=EOF err.helps=[help] wrapped="wrapped: other" plain=EOF
+error: test
+  --> EOF
+   = help: help
+
+error: other
+
+`, out.String())
+}
+
+func Test_DiagnosticHandler_Nil(t *testing.T) {
+	var out bytes.Buffer
+
+	logger := slog.New(NewDiagnosticHandler(&out, slog.NewTextHandler(&out, &slog.HandlerOptions{ReplaceAttr: removeTime})))
+
+	var err *Error
+	logger.Error("failed", "err", err, "cause", New("test").Cause(err))
+
+	assert.Equal(t, "level=ERROR msg=failed err=<nil> cause.message=test cause.severity=error cause.cause=<nil>\nerror: test\n\n", out.String())
+}
+
+func Test_DiagnosticHandler_Attrs_And_Groups(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	var (
+		out         bytes.Buffer
+		diagnostics bytes.Buffer
+	)
+
+	logger := slog.New(NewDiagnosticHandler(&diagnostics, slog.NewJSONHandler(&out, &slog.HandlerOptions{ReplaceAttr: removeTime}))).
+		With("request", New("from with").Code(1)).
+		WithGroup("g")
+
+	logger.Error("failed", slog.Group("details", "err", New("in group")))
+
+	assert.JSONEq(t, `{
+		"level": "ERROR",
+		"msg": "failed",
+		"request": {"message": "from with", "severity": "error", "code": "E0001"},
+		"g": {"details": {"err": {"message": "in group", "severity": "error"}}}
+	}`, out.String())
+
+	assert.Equal(t, "error[E0001]: from with\n\nerror: in group\n\n", diagnostics.String())
+}
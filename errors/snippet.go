@@ -0,0 +1,635 @@
+package errors
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// lines that are displayed at the beginning and at the end of a long multi-line label
+	multilineContext = 2
+	// rustc displays tabs as 4 spaces
+	tabWidth = 4
+
+	styleNone      = ""
+	stylePrimary   = "primary"
+	styleSecondary = "secondary"
+	styleAdded     = "added"
+	styleRemoved   = "removed"
+)
+
+// Snippet is a part of a source file that the labels of an error point into.
+type Snippet struct {
+	File      string `json:"file"`
+	FirstLine int    `json:"first_line"`
+	Source    string `json:"source"`
+}
+
+// Label marks a span of the source code.
+// Line and Column are 1-based, Span is the number of characters the label covers
+// and may continue on the following lines.
+type Label struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Span    int    `json:"span"`
+	Text    string `json:"text,omitempty"`
+	Primary bool   `json:"primary"`
+}
+
+// Snippet adds the source code of a file starting at the given line,
+// labels of the file are rendered with the lines of this source.
+func (e *Error) Snippet(file string, firstLine int, source string) *Error {
+	e = e.mutable()
+	e.snippets = append(e.snippets, Snippet{
+		File:      file,
+		FirstLine: firstLine,
+		Source:    source,
+	})
+
+	return e
+}
+
+// Label adds a primary label that is underlined with '^'.
+// Lines and columns below 1 are set to 1.
+func (e *Error) Label(file string, line, col, span int, text string) *Error {
+	e = e.mutable()
+	e.labels = append(e.labels, Label{
+		File:    file,
+		Line:    max(line, 1),
+		Column:  max(col, 1),
+		Span:    span,
+		Text:    text,
+		Primary: true,
+	})
+
+	return e
+}
+
+// SecondaryLabel adds a label that is underlined with '-'.
+// Lines and columns below 1 are set to 1.
+func (e *Error) SecondaryLabel(file string, line, col, span int, text string) *Error {
+	e = e.mutable()
+	e.labels = append(e.labels, Label{
+		File:   file,
+		Line:   max(line, 1),
+		Column: max(col, 1),
+		Span:   span,
+		Text:   text,
+	})
+
+	return e
+}
+
+func (e *Error) Snippets() []Snippet {
+	return e.snippets
+}
+
+func (e *Error) Labels() []Label {
+	return e.labels
+}
+
+// snippetView is the rendered form of the labels of one file, used by the "snippets" template.
+type snippetView struct {
+	Arrow    string
+	Indent   string
+	Location string
+	Lines    []snippetLine
+}
+
+type snippetLine struct {
+	Number   string
+	Gap      bool
+	Segments []snippetSegment
+}
+
+type snippetSegment struct {
+	Text  string
+	Style string
+}
+
+type resolvedLabel struct {
+	Label
+	endLine   int
+	endColumn int
+	slash     bool
+	margin    int
+}
+
+func (l *resolvedLabel) multiline() bool {
+	return l.endLine > l.Line
+}
+
+func (l *resolvedLabel) style() string {
+	if l.Primary {
+		return stylePrimary
+	}
+
+	return styleSecondary
+}
+
+func (l *resolvedLabel) marker() rune {
+	if l.Primary {
+		return '^'
+	}
+
+	return '-'
+}
+
+// sourceFile collects the lines of every snippet of a file.
+type sourceFile map[int]string
+
+func (s sourceFile) line(number int) (string, bool) {
+	line, ok := s[number]
+
+	return line, ok
+}
+
+// snippetViews lays out the labels by files,
+// the footer adds an empty line after the last snippet that separates it from the notes and helps.
+func (e *Error) snippetViews(footer bool) []snippetView {
+	if len(e.labels) == 0 {
+		return nil
+	}
+
+	var files []string
+
+	sources := e.sources()
+	labels := make(map[string][]*resolvedLabel)
+
+	maxLine := 0
+
+	for _, label := range e.labels {
+		if _, ok := labels[label.File]; !ok {
+			files = append(files, label.File)
+		}
+
+		resolved := resolveLabel(label, sources[label.File])
+		labels[label.File] = append(labels[label.File], resolved)
+
+		maxLine = max(maxLine, resolved.endLine)
+	}
+
+	// the gutter is at least 2 characters wide so that it is aligned with the notes and helps
+	width := max(2, len(strconv.Itoa(maxLine)))
+
+	result := make([]snippetView, 0, len(files))
+
+	for i, file := range files {
+		arrow := "-->"
+		if i > 0 {
+			arrow = ":::"
+		}
+
+		result = append(result, snippetView{
+			Arrow:    arrow,
+			Indent:   strings.Repeat(" ", width),
+			Location: location(file, labels[file]),
+			Lines:    layoutSnippet(labels[file], sources[file], width),
+		})
+	}
+
+	if footer {
+		last := &result[len(result)-1]
+		last.Lines = append(last.Lines, snippetLine{Number: strings.Repeat(" ", width)})
+	}
+
+	return result
+}
+
+// sources collects the lines of the snippets by files.
+func (e *Error) sources() map[string]sourceFile {
+	result := make(map[string]sourceFile)
+
+	for file, lines := range SourceLines(e.snippets) {
+		result[file] = lines
+	}
+
+	return result
+}
+
+// SourceLines returns the lines of the snippets by their files and line numbers,
+// the lines of the later snippets replace the lines of the earlier ones.
+func SourceLines(snippets []Snippet) map[string]map[int]string {
+	result := make(map[string]map[int]string)
+
+	for _, snippet := range snippets {
+		if _, ok := result[snippet.File]; !ok {
+			result[snippet.File] = make(map[int]string)
+		}
+
+		for i, line := range strings.Split(snippet.Source, "\n") {
+			result[snippet.File][snippet.FirstLine+i] = strings.TrimSuffix(line, "\r")
+		}
+	}
+
+	return result
+}
+
+func location(file string, labels []*resolvedLabel) string {
+	primary := labels[0]
+
+	for _, label := range labels {
+		if label.Primary {
+			primary = label
+			break
+		}
+	}
+
+	return file + ":" + strconv.Itoa(primary.Line) + ":" + strconv.Itoa(primary.Column)
+}
+
+// resolveLabel calculates where the span of the label ends.
+// The newline at the end of a line counts as one character.
+func resolveLabel(label Label, source sourceFile) *resolvedLabel {
+	// the labels of the decoded errors are not checked by Label
+	label.Line, label.Column = max(label.Line, 1), max(label.Column, 1)
+
+	result := &resolvedLabel{
+		Label:     label,
+		endLine:   label.Line,
+		endColumn: label.Column + max(label.Span, 1) - 1,
+	}
+
+	line, col, remaining := label.Line, label.Column, max(label.Span, 1)
+
+	for {
+		text, ok := source.line(line)
+		if !ok {
+			break
+		}
+
+		available := len([]rune(text)) - col + 1
+		if remaining <= available || remaining == available+1 {
+			result.endLine = line
+			result.endColumn = col + remaining - 1
+
+			break
+		}
+
+		if _, ok := source.line(line + 1); !ok {
+			result.endLine = line
+			result.endColumn = col + remaining - 1
+
+			break
+		}
+
+		remaining -= available + 1
+		line++
+		col = 1
+	}
+
+	if result.multiline() {
+		text, _ := source.line(label.Line)
+		indentation := len([]rune(text)) - len([]rune(strings.TrimLeft(text, " \t")))
+		result.slash = label.Column <= indentation+1
+	}
+
+	return result
+}
+
+func layoutSnippet(labels []*resolvedLabel, source sourceFile, width int) []snippetLine {
+	var multiline []*resolvedLabel
+
+	for _, label := range labels {
+		if label.multiline() {
+			multiline = append(multiline, label)
+		}
+	}
+
+	slices.SortStableFunc(multiline, func(a, b *resolvedLabel) int {
+		return a.Line - b.Line
+	})
+
+	for i, label := range multiline {
+		label.margin = i
+	}
+
+	marginWidth := 0
+	if len(multiline) > 0 {
+		marginWidth = len(multiline) + 1
+	}
+
+	l := &snippetLayout{
+		labels:      labels,
+		multiline:   multiline,
+		source:      source,
+		width:       width,
+		marginWidth: marginWidth,
+	}
+
+	l.lines = append(l.lines, snippetLine{Number: strings.Repeat(" ", width)})
+
+	previous := 0
+
+	for _, number := range displayedLines(labels) {
+		if previous > 0 && number > previous+1 {
+			l.lines = append(l.lines, snippetLine{Gap: true})
+		}
+
+		l.sourceRow(number)
+		l.singleLineRows(number)
+		l.multilineRows(number)
+
+		previous = number
+	}
+
+	return l.lines
+}
+
+// displayedLines returns the lines that have labels.
+// Lines between the labels are displayed only if they are
+// part of a short multi-line label or if they fill a one line gap.
+func displayedLines(labels []*resolvedLabel) []int {
+	var lines []int
+
+	for _, label := range labels {
+		if label.endLine-label.Line <= 2*multilineContext {
+			for line := label.Line; line <= label.endLine; line++ {
+				lines = append(lines, line)
+			}
+
+			continue
+		}
+
+		for i := range multilineContext {
+			lines = append(lines, label.Line+i, label.endLine-i)
+		}
+	}
+
+	slices.Sort(lines)
+	lines = slices.Compact(lines)
+
+	result := make([]int, 0, len(lines))
+
+	for i, line := range lines {
+		if i > 0 && line == lines[i-1]+2 {
+			result = append(result, line-1)
+		}
+
+		result = append(result, line)
+	}
+
+	return result
+}
+
+type snippetLayout struct {
+	labels      []*resolvedLabel
+	multiline   []*resolvedLabel
+	source      sourceFile
+	width       int
+	marginWidth int
+
+	lines []snippetLine
+}
+
+func (l *snippetLayout) sourceRow(number int) {
+	text, _ := l.source.line(number)
+
+	row := newCanvas()
+	l.margin(row, number, nil)
+
+	for _, label := range l.multiline {
+		if label.Line == number && label.slash {
+			row.put(1+label.margin, "/", label.style())
+		}
+	}
+
+	row.put(1+l.marginWidth, expandTabs(text), styleNone)
+
+	l.lines = append(l.lines, snippetLine{
+		Number:   padLeft(strconv.Itoa(number), l.width),
+		Segments: row.segments(),
+	})
+}
+
+// singleLineRows renders the markers of the labels that start and end on the line.
+// The text of the rightmost label is placed after its markers,
+// the others are connected to their markers with vertical lines.
+func (l *snippetLayout) singleLineRows(number int) {
+	var labels []*resolvedLabel
+
+	for _, label := range l.labels {
+		if !label.multiline() && label.Line == number {
+			labels = append(labels, label)
+		}
+	}
+
+	if len(labels) == 0 {
+		return
+	}
+
+	slices.SortStableFunc(labels, func(a, b *resolvedLabel) int {
+		return a.Column - b.Column
+	})
+
+	text, _ := l.source.line(number)
+
+	markers := newCanvas()
+	l.margin(markers, number, nil)
+
+	// secondary markers are drawn first so that the primary ones are on the top
+	for _, primary := range []bool{false, true} {
+		for _, label := range labels {
+			if label.Primary != primary {
+				continue
+			}
+
+			start, end := displayColumn(text, label.Column), displayColumn(text, label.endColumn+1)
+			markers.put(l.marginWidth+start, strings.Repeat(string(label.marker()), max(end-start, 1)), label.style())
+		}
+	}
+
+	last := labels[len(labels)-1]
+	if last.Text != "" {
+		end := displayColumn(text, last.endColumn+1)
+		markers.put(l.marginWidth+end+1, last.Text, last.style())
+	}
+
+	l.lines = append(l.lines, l.annotationLine(markers))
+
+	var pending []*resolvedLabel
+
+	for _, label := range labels[:len(labels)-1] {
+		if label.Text != "" {
+			pending = append(pending, label)
+		}
+	}
+
+	if len(pending) == 0 {
+		return
+	}
+
+	connectors := func(labels []*resolvedLabel) *canvas {
+		row := newCanvas()
+		l.margin(row, number, nil)
+
+		for _, label := range labels {
+			row.put(l.marginWidth+displayColumn(text, label.Column), "|", label.style())
+		}
+
+		return row
+	}
+
+	l.lines = append(l.lines, l.annotationLine(connectors(pending)))
+
+	for i := len(pending) - 1; i >= 0; i-- {
+		row := connectors(pending[:i])
+		row.put(l.marginWidth+displayColumn(text, pending[i].Column), pending[i].Text, pending[i].style())
+
+		l.lines = append(l.lines, l.annotationLine(row))
+	}
+}
+
+// multilineRows renders the beginning and the end of the multi-line labels.
+func (l *snippetLayout) multilineRows(number int) {
+	text, _ := l.source.line(number)
+
+	for _, label := range l.multiline {
+		if label.Line != number || label.slash {
+			continue
+		}
+
+		row := newCanvas()
+		l.margin(row, number, nil)
+
+		start := displayColumn(text, label.Column)
+		row.put(2+label.margin, strings.Repeat("_", l.marginWidth+start-2-label.margin), label.style())
+		row.put(l.marginWidth+start, string(label.marker()), label.style())
+
+		l.lines = append(l.lines, l.annotationLine(row))
+	}
+
+	// inner labels are closed first
+	closed := make(map[*resolvedLabel]bool)
+
+	for i := len(l.multiline) - 1; i >= 0; i-- {
+		label := l.multiline[i]
+		if label.endLine != number {
+			continue
+		}
+
+		row := newCanvas()
+		l.margin(row, number, closed)
+
+		end := displayColumn(text, label.endColumn)
+		row.put(2+label.margin, strings.Repeat("_", l.marginWidth+end-2-label.margin), label.style())
+		row.put(l.marginWidth+end, string(label.marker()), label.style())
+
+		if label.Text != "" {
+			row.put(l.marginWidth+end+2, label.Text, label.style())
+		}
+
+		l.lines = append(l.lines, l.annotationLine(row))
+
+		closed[label] = true
+	}
+}
+
+// margin draws the vertical lines of the multi-line labels that are open on the line.
+// The skipped labels are not drawn because they are already closed.
+func (l *snippetLayout) margin(row *canvas, number int, skip map[*resolvedLabel]bool) {
+	for _, label := range l.multiline {
+		if skip[label] {
+			continue
+		}
+
+		started := label.Line < number || (label.Line == number && label.slash)
+		if started && number <= label.endLine {
+			row.put(1+label.margin, "|", label.style())
+		}
+	}
+}
+
+func (l *snippetLayout) annotationLine(row *canvas) snippetLine {
+	return snippetLine{
+		Number:   strings.Repeat(" ", l.width),
+		Segments: row.segments(),
+	}
+}
+
+// canvas is a single row of the rendered snippet.
+// Position 0 is the column right after the gutter.
+type canvas struct {
+	cells []cell
+}
+
+type cell struct {
+	r     rune
+	style string
+}
+
+func newCanvas() *canvas {
+	return &canvas{}
+}
+
+func (c *canvas) put(position int, text, style string) {
+	for _, r := range text {
+		for len(c.cells) <= position {
+			c.cells = append(c.cells, cell{r: ' '})
+		}
+
+		c.cells[position] = cell{r: r, style: style}
+		position++
+	}
+}
+
+// segments merges the cells with the same style, the trailing whitespace is removed.
+func (c *canvas) segments() []snippetSegment {
+	end := len(c.cells)
+	for end > 0 && c.cells[end-1].r == ' ' {
+		end--
+	}
+
+	var result []snippetSegment
+
+	for _, cell := range c.cells[:end] {
+		style := cell.style
+		if cell.r == ' ' {
+			style = styleNone
+		}
+
+		if len(result) > 0 && result[len(result)-1].Style == style {
+			result[len(result)-1].Text += string(cell.r)
+			continue
+		}
+
+		result = append(result, snippetSegment{Text: string(cell.r), Style: style})
+	}
+
+	return result
+}
+
+// displayColumn converts the 1-based column of a character to the column where it is displayed,
+// tabs and wide characters take more than one column.
+func displayColumn(line string, col int) int {
+	result := 1
+
+	for i, r := range []rune(line) {
+		if i+1 >= col {
+			return result
+		}
+
+		if r == '\t' {
+			result += tabWidth
+		} else {
+			result += runeWidth(r)
+		}
+	}
+
+	// the column is after the end of the line
+	return result + col - 1 - len([]rune(line))
+}
+
+func expandTabs(line string) string {
+	return strings.ReplaceAll(line, "\t", strings.Repeat(" ", tabWidth))
+}
+
+func padLeft(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+
+	return strings.Repeat(" ", width-len(s)) + s
+}
@@ -0,0 +1,198 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	iteratorSource = `fn main() {
+    for foo in Foo {}
+	let x = bar(a, b);
+}`
+
+	functionSource = `fn foo() -> i32 {
+    if true {
+        1
+    }
+}`
+)
+
+func Test_Snippets(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	for _, tt := range []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name: "single label",
+			err: New("'Foo' is not an iterator").
+				Code(277).
+				Snippet("src/main.rs", 3, iteratorSource).
+				Label("src/main.rs", 4, 16, 3, "'Foo' is not an iterator").
+				Note("maybe try calling '.iter()' or a similar method"),
+			expected: `error[E0277]: 'Foo' is not an iterator
+  --> src/main.rs:4:16
+   |
+ 4 |     for foo in Foo {}
+   |                ^^^ 'Foo' is not an iterator
+   |
+   = note: maybe try calling '.iter()' or a similar method`,
+		},
+		{
+			name: "multiple labels on the same line, tabs are expanded",
+			err: New("test").
+				Snippet("src/main.rs", 3, iteratorSource).
+				Label("src/main.rs", 5, 10, 3, "first").
+				SecondaryLabel("src/main.rs", 5, 14, 1, "second").
+				SecondaryLabel("src/main.rs", 5, 17, 1, "third"),
+			expected: `error: test
+  --> src/main.rs:5:10
+   |
+ 5 |     let x = bar(a, b);
+   |             ^^^ -  - third
+   |             |   |
+   |             |   second
+   |             first`,
+		},
+		{
+			name: "multi-line label starting at the beginning of the line",
+			err: New("mismatched types").
+				Code(308).
+				Snippet("src/main.rs", 1, functionSource).
+				SecondaryLabel("src/main.rs", 1, 13, 3, "expected `i32` because of return type").
+				Label("src/main.rs", 2, 5, 25, "expected `i32`, found `()`"),
+			expected: "error[E0308]: mismatched types\n" +
+				"  --> src/main.rs:2:5\n" +
+				"   |\n" +
+				" 1 |   fn foo() -> i32 {\n" +
+				"   |               --- expected `i32` because of return type\n" +
+				" 2 | /     if true {\n" +
+				" 3 | |         1\n" +
+				" 4 | |     }\n" +
+				"   | |_____^ expected `i32`, found `()`",
+		},
+		{
+			name: "multi-line label starting in the middle of the line",
+			err: New("test").
+				Snippet("src/main.rs", 1, functionSource).
+				Label("src/main.rs", 1, 17, 33, "body").
+				Help("remove the body"),
+			expected: `error: test
+  --> src/main.rs:1:17
+   |
+ 1 |   fn foo() -> i32 {
+   |  _________________^
+ 2 | |     if true {
+ 3 | |         1
+ 4 | |     }
+ 5 | | }
+   | |_^ body
+   |
+   = help: remove the body`,
+		},
+		{
+			name: "labels in multiple files",
+			err: New("test").
+				Snippet("src/main.rs", 1, functionSource).
+				Snippet("src/lib.rs", 98, functionSource).
+				Label("src/main.rs", 1, 4, 3, "here").
+				SecondaryLabel("src/lib.rs", 98, 1, 2, "first").
+				SecondaryLabel("src/lib.rs", 102, 1, 1, "last"),
+			expected: `error: test
+   --> src/main.rs:1:4
+    |
+  1 | fn foo() -> i32 {
+    |    ^^^ here
+   ::: src/lib.rs:98:1
+    |
+ 98 | fn foo() -> i32 {
+    | -- first
+...
+102 | }
+    | - last`,
+		},
+		{
+			name: "wide characters",
+			err: New("test").
+				Snippet("src/main.rs", 1, "let 名前 = 値;").
+				Label("src/main.rs", 1, 5, 2, "name").
+				SecondaryLabel("src/main.rs", 1, 10, 1, "value"),
+			expected: `error: test
+  --> src/main.rs:1:5
+   |
+ 1 | let 名前 = 値;
+   |     ^^^^   -- value
+   |     |
+   |     name`,
+		},
+		{
+			name: "label without source",
+			err: New("test").
+				Label("config.yaml", 12, 3, 4, "unknown key"),
+			expected: `error: test
+  --> config.yaml:12:3
+   |
+12 |
+   |   ^^^^ unknown key`,
+		},
+		{
+			name: "lines and columns below 1",
+			err: New("x").
+				Snippet("a", 1, "abc").
+				Label("a", 1, -1, 2, "t"),
+			expected: `error: x
+  --> a:1:1
+   |
+ 1 | abc
+   | ^^ t`,
+		},
+		{
+			name: "secondary label on line 0",
+			err: New("x").
+				Snippet("a", 1, "abc").
+				SecondaryLabel("a", 0, 3, 1, "t"),
+			expected: `error: x
+  --> a:1:3
+   |
+ 1 | abc
+   |   - t`,
+		},
+		{
+			name: "decoded labels are not checked",
+			err:  &Error{message: "x", labels: []Label{{File: "a", Line: -2, Column: -1, Span: 1, Primary: true}}},
+			expected: `error: x
+  --> a:1:1
+   |
+ 1 |
+   | ^`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.err.Error())
+		})
+	}
+}
+
+func Test_Snippets_Custom_Template(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	init := NewInitializer(WithTemplateDefinition(TemplateDefinitionSnippets, `{{- define "snippets" }}
+{{- range .Snippets }}
+at {{ .Location }}
+{{- end }}
+{{- end }}`))
+
+	err := init.NewError("test").
+		Label("src/main.rs", 4, 16, 3, "label")
+
+	assert.Equal(t, "error: test\nat src/main.rs:4:16", err.Error())
+}
@@ -0,0 +1,53 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Frame is a function call of the stack trace.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+func (f Frame) String() string {
+	return fmt.Sprintf("%s (%s:%d)", f.Function, f.File, f.Line)
+}
+
+// StackTrace returns the call stack where the error was created.
+// It is empty unless stack traces are enabled with WithStackTrace or SetStackTraceDepth.
+func (e *Error) StackTrace() []Frame {
+	return e.stack
+}
+
+// callers returns at most depth frames,
+// skip is the number of frames to skip above the caller of callers.
+func callers(skip, depth int) []Frame {
+	pcs := make([]uintptr, depth)
+	n := runtime.Callers(skip+2, pcs)
+
+	result := make([]Frame, 0, n)
+	if n == 0 {
+		return result
+	}
+
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+
+		result = append(result, Frame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		})
+
+		if !more {
+			break
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,307 @@
+package errors
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/agext/levenshtein"
+)
+
+// Algorithm measures how close the input is to an available value.
+type Algorithm int
+
+const (
+	// AlgorithmLevenshtein counts the insertions, deletions and substitutions.
+	AlgorithmLevenshtein Algorithm = iota
+	// AlgorithmDamerauLevenshtein counts the transpositions of adjacent characters as single edits.
+	AlgorithmDamerauLevenshtein
+	// AlgorithmJaroWinkler measures the similarity between 0 and 1, it favors common prefixes.
+	AlgorithmJaroWinkler
+)
+
+// defaultSimilarity is the minimum Jaro-Winkler similarity of the suggestions by default.
+const defaultSimilarity = 0.8
+
+// defaultThreshold selects the default threshold of the algorithm.
+const defaultThreshold = -1
+
+type SuggestOption func(*suggestOptions)
+
+type suggestOptions struct {
+	algorithm       Algorithm
+	threshold       float64
+	caseInsensitive bool
+	prefix          bool
+	max             int
+}
+
+// WithAlgorithm changes the algorithm that measures the distance, Levenshtein is used by default.
+func WithAlgorithm(algorithm Algorithm) SuggestOption {
+	return func(opts *suggestOptions) {
+		opts.algorithm = algorithm
+	}
+}
+
+// WithThreshold changes which values are close enough to be suggested.
+// It is the maximum number of edits for Levenshtein and Damerau-Levenshtein
+// and the minimum similarity for Jaro-Winkler.
+// By default the number of edits must be less than the length of the input
+// and the similarity must be at least 0.8, a negative threshold selects the default.
+// A threshold of 0 edits suggests the exact matches only.
+func WithThreshold(threshold float64) SuggestOption {
+	return func(opts *suggestOptions) {
+		opts.threshold = threshold
+	}
+}
+
+// WithCaseInsensitive ignores the case of the letters when the values are compared.
+func WithCaseInsensitive() SuggestOption {
+	return func(opts *suggestOptions) {
+		opts.caseInsensitive = true
+	}
+}
+
+// WithPrefixMatching suggests the values that start with the input before the others regardless of their distance.
+func WithPrefixMatching() SuggestOption {
+	return func(opts *suggestOptions) {
+		opts.prefix = true
+	}
+}
+
+// WithMaxSuggestions limits the number of the listed values, 0 lists all of them.
+func WithMaxSuggestions(max int) SuggestOption {
+	return func(opts *suggestOptions) {
+		opts.max = max
+	}
+}
+
+// SuggestValue provides suggestions for the input based on the available values.
+// useful when the error occurs because of a wrong input value.
+// if the input is an emtpy string or none of the values are close to it, it will list all available values.
+// The suggestions are ordered by their distance, ties are ordered alphabetically.
+func (e *Error) SuggestValue(input string, available []string, opts ...SuggestOption) *Error {
+	e = e.mutable()
+
+	options := &suggestOptions{threshold: defaultThreshold}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if input == "" {
+		e.suggestValuesHelp(available, true, options.max)
+		return e
+	}
+
+	suggested := options.suggest(input, available)
+
+	if len(suggested) == 0 {
+		sorted := slices.Clone(available)
+		slices.Sort(sorted)
+		e.suggestValuesHelp(sorted, true, options.max)
+	} else {
+		e.suggestValuesHelp(suggested, false, options.max)
+	}
+
+	return e
+}
+
+func (e *Error) suggestValuesHelp(suggestions []string, all bool, max int) {
+	if len(suggestions) == 1 {
+		e.helps = append(e.helps, fmt.Sprintf("did you mean: '%s'?", suggestions[0]))
+		return
+	}
+
+	more := 0
+	if max > 0 && len(suggestions) > max {
+		suggestions, more = suggestions[:max], len(suggestions)-max
+	}
+
+	sb := strings.Builder{}
+
+	if all {
+		sb.WriteString("available values:\n- ")
+	} else {
+		sb.WriteString("did you mean any of these?\n- ")
+	}
+
+	sb.WriteString(strings.Join(suggestions, "\n- "))
+
+	if more > 0 {
+		_, _ = fmt.Fprintf(&sb, "\n- ... and %d more", more)
+	}
+
+	e.helps = append(e.helps, sb.String())
+}
+
+type suggestion struct {
+	value    string
+	prefix   bool
+	distance float64
+}
+
+// suggest returns the values that are close to the input, the closest first.
+func (o *suggestOptions) suggest(input string, available []string) []string {
+	var suggestions []suggestion
+
+	for _, value := range available {
+		if s, ok := o.match(input, value); ok {
+			suggestions = append(suggestions, s)
+		}
+	}
+
+	slices.SortFunc(suggestions, func(a, b suggestion) int {
+		if a.prefix != b.prefix {
+			if a.prefix {
+				return -1
+			}
+
+			return 1
+		}
+
+		return cmp.Or(cmp.Compare(a.distance, b.distance), strings.Compare(a.value, b.value))
+	})
+
+	result := make([]string, 0, len(suggestions))
+	for _, s := range suggestions {
+		result = append(result, s.value)
+	}
+
+	return result
+}
+
+// match measures the distance of the value from the input, the lower the closer.
+func (o *suggestOptions) match(input, value string) (suggestion, bool) {
+	result := suggestion{value: value}
+
+	if o.caseInsensitive {
+		input, value = strings.ToLower(input), strings.ToLower(value)
+	}
+
+	result.prefix = o.prefix && strings.HasPrefix(value, input)
+
+	var ok bool
+
+	switch o.algorithm {
+	case AlgorithmJaroWinkler:
+		similarity := jaroWinkler(input, value)
+		result.distance = 1 - similarity
+		threshold := o.threshold
+		if threshold < 0 {
+			threshold = defaultSimilarity
+		}
+
+		ok = similarity >= threshold
+	default:
+		distance := levenshtein.Distance(input, value, nil)
+		if o.algorithm == AlgorithmDamerauLevenshtein {
+			distance = damerauLevenshtein(input, value)
+		}
+
+		result.distance = float64(distance)
+
+		// distance >= len(input) >> the input does not contain the item
+		if o.threshold >= 0 {
+			ok = result.distance <= o.threshold
+		} else {
+			ok = distance < utf8.RuneCountInString(input)
+		}
+	}
+
+	return result, ok || result.prefix
+}
+
+// damerauLevenshtein returns the optimal string alignment distance of the strings:
+// the number of insertions, deletions, substitutions and transpositions of adjacent characters.
+func damerauLevenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of the strings between 0 and 1.
+func jaroWinkler(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	window := max(max(len(ra), len(rb))/2-1, 0)
+	matchedA, matchedB := make([]bool, len(ra)), make([]bool, len(rb))
+	matches := 0
+
+	for i := range ra {
+		for j := max(0, i-window); j < min(len(rb), i+window+1); j++ {
+			if !matchedB[j] && ra[i] == rb[j] {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+
+				break
+			}
+		}
+	}
+
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, k := 0, 0
+
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+
+		for !matchedB[k] {
+			k++
+		}
+
+		if ra[i] != rb[k] {
+			transpositions++
+		}
+
+		k++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -0,0 +1,140 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Suggest_Value(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	for _, tt := range []struct {
+		name      string
+		input     string
+		available []string
+		opts      []SuggestOption
+		expected  string
+	}{
+		{
+			name:      "ties are ordered alphabetically",
+			input:     "bat",
+			available: []string{"rat", "cat", "hat"},
+			expected: `error: test
+   = help: did you mean any of these?
+           - cat
+           - hat
+           - rat`,
+		},
+		{
+			name:      "transposition with Levenshtein",
+			input:     "recieve",
+			available: []string{"receive", "relieve"},
+			expected: `error: test
+   = help: did you mean any of these?
+           - relieve
+           - receive`,
+		},
+		{
+			name:      "transposition with Damerau-Levenshtein",
+			input:     "recieve",
+			available: []string{"receive", "relieve"},
+			opts:      []SuggestOption{WithAlgorithm(AlgorithmDamerauLevenshtein)},
+			expected: `error: test
+   = help: did you mean any of these?
+           - receive
+           - relieve`,
+		},
+		{
+			name:      "Jaro-Winkler",
+			input:     "martha",
+			available: []string{"marhta", "maria", "jones"},
+			opts:      []SuggestOption{WithAlgorithm(AlgorithmJaroWinkler)},
+			expected: `error: test
+   = help: did you mean any of these?
+           - marhta
+           - maria`,
+		},
+		{
+			name:      "case insensitive",
+			input:     "YAML",
+			available: []string{"yaml", "json"},
+			opts:      []SuggestOption{WithCaseInsensitive()},
+			expected: `error: test
+   = help: did you mean: 'yaml'?`,
+		},
+		{
+			name:      "prefix",
+			input:     "conf",
+			available: []string{"configuration", "cont", "data"},
+			opts:      []SuggestOption{WithPrefixMatching()},
+			expected: `error: test
+   = help: did you mean any of these?
+           - configuration
+           - cont`,
+		},
+		{
+			name:      "threshold",
+			input:     "kitten",
+			available: []string{"sitting", "mitten"},
+			opts:      []SuggestOption{WithThreshold(1)},
+			expected: `error: test
+   = help: did you mean: 'mitten'?`,
+		},
+		{
+			name:      "exact matches only",
+			input:     "Kitten",
+			available: []string{"kitten", "mitten"},
+			opts:      []SuggestOption{WithThreshold(0), WithCaseInsensitive()},
+			expected: `error: test
+   = help: did you mean: 'kitten'?`,
+		},
+		{
+			name:      "negative threshold is the default",
+			input:     "kitten",
+			available: []string{"sitting", "mitten"},
+			opts:      []SuggestOption{WithThreshold(-1)},
+			expected: `error: test
+   = help: did you mean any of these?
+           - mitten
+           - sitting`,
+		},
+		{
+			name:      "max suggestions",
+			input:     "",
+			available: []string{"one", "two", "three", "four"},
+			opts:      []SuggestOption{WithMaxSuggestions(2)},
+			expected: `error: test
+   = help: available values:
+           - one
+           - two
+           - ... and 2 more`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, New("test").SuggestValue(tt.input, tt.available, tt.opts...).Error())
+		})
+	}
+}
+
+func Test_Suggest_Value_Does_Not_Sort_Available(t *testing.T) {
+	available := []string{"two", "one"}
+
+	New("test").SuggestValue("x", available)
+
+	assert.Equal(t, []string{"two", "one"}, available)
+}
+
+func Test_Distances(t *testing.T) {
+	assert.Equal(t, 1, damerauLevenshtein("teh", "the"))
+	assert.Equal(t, 3, damerauLevenshtein("kitten", "sitting"))
+	assert.Equal(t, 0, damerauLevenshtein("", ""))
+
+	assert.InDelta(t, 0.961, jaroWinkler("martha", "marhta"), 0.001)
+	assert.InDelta(t, 0.840, jaroWinkler("dwayne", "duane"), 0.001)
+	assert.InDelta(t, 1.0, jaroWinkler("same", "same"), 0.001)
+	assert.InDelta(t, 0.0, jaroWinkler("abc", ""), 0.001)
+}
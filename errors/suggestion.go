@@ -0,0 +1,253 @@
+package errors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Applicability tells whether a suggestion can be applied by tools without a review.
+type Applicability int
+
+const (
+	ApplicabilityUnspecified Applicability = iota
+	// ApplicabilityMachineApplicable suggestions are certainly correct, they can be applied automatically.
+	ApplicabilityMachineApplicable
+	// ApplicabilityMaybeIncorrect suggestions are probably what the user wants but they should be reviewed.
+	ApplicabilityMaybeIncorrect
+	// ApplicabilityHasPlaceholders suggestions contain placeholders like "(...)" that have to be filled in.
+	ApplicabilityHasPlaceholders
+)
+
+var applicabilityNames = map[Applicability]string{
+	ApplicabilityUnspecified:       "Unspecified",
+	ApplicabilityMachineApplicable: "MachineApplicable",
+	ApplicabilityMaybeIncorrect:    "MaybeIncorrect",
+	ApplicabilityHasPlaceholders:   "HasPlaceholders",
+}
+
+func (a Applicability) String() string {
+	if name, ok := applicabilityNames[a]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Applicability(%d)", int(a))
+}
+
+func (a Applicability) MarshalText() ([]byte, error) {
+	if _, ok := applicabilityNames[a]; !ok {
+		return nil, fmt.Errorf("unknown applicability: %d", int(a))
+	}
+
+	return []byte(a.String()), nil
+}
+
+func (a *Applicability) UnmarshalText(text []byte) error {
+	for applicability, name := range applicabilityNames {
+		if name == string(text) {
+			*a = applicability
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown applicability: %q", text)
+}
+
+// Suggestion replaces a span of the source code to fix the error.
+// Line and Column are 1-based, Span is the number of characters that are replaced
+// and may continue on the following lines.
+type Suggestion struct {
+	Message       string        `json:"message"`
+	File          string        `json:"file"`
+	Line          int           `json:"line"`
+	Column        int           `json:"column"`
+	Span          int           `json:"span"`
+	Original      string        `json:"original"`
+	Replacement   string        `json:"replacement"`
+	Applicability Applicability `json:"applicability"`
+}
+
+const defaultSuggestionMessage = "try this"
+
+// Suggest adds a suggestion that replaces the original text at the label with the replacement.
+// The text of the label is the message of the suggestion,
+// the span of the label is the length of the original text if it is not set.
+// Suggestions with a negative span are rendered as helps without the source code.
+// The suggestion should be reviewed before it is applied, see SuggestWithApplicability.
+func (e *Error) Suggest(label Label, original, replacement string) *Error {
+	return e.SuggestWithApplicability(label, original, replacement, ApplicabilityMaybeIncorrect)
+}
+
+// SuggestWithApplicability adds a suggestion like Suggest with the given applicability.
+func (e *Error) SuggestWithApplicability(label Label, original, replacement string, applicability Applicability) *Error {
+	message := label.Text
+	if message == "" {
+		message = defaultSuggestionMessage
+	}
+
+	span := label.Span
+	if span == 0 {
+		span = utf8.RuneCountInString(original)
+	}
+
+	e = e.mutable()
+	e.suggestions = append(e.suggestions, Suggestion{
+		Message:       message,
+		File:          label.File,
+		Line:          label.Line,
+		Column:        label.Column,
+		Span:          span,
+		Original:      original,
+		Replacement:   replacement,
+		Applicability: applicability,
+	})
+
+	return e
+}
+
+func (e *Error) Suggestions() []Suggestion {
+	return e.suggestions
+}
+
+// suggestionView is the rendered form of a suggestion, used by the "suggestions" template.
+// There are no lines if the source code of the suggestion is not available.
+type suggestionView struct {
+	Suggestion
+	Indent string
+	Lines  []suggestionLine
+}
+
+// suggestionLine is a line of the patched source code,
+// the marker is "|" for the lines that are displayed with their changes underlined
+// and "-" or "+" for the removed and the added lines.
+type suggestionLine struct {
+	Number   string
+	Marker   string
+	Segments []snippetSegment
+}
+
+// suggestionViews lays out the suggestions with a gutter that is at least width wide.
+func (e *Error) suggestionViews(width int) []suggestionView {
+	if len(e.suggestions) == 0 {
+		return nil
+	}
+
+	sources := e.sources()
+	result := make([]suggestionView, 0, len(e.suggestions))
+
+	for _, suggestion := range e.suggestions {
+		view := suggestionView{Suggestion: suggestion}
+
+		if lines, ok := suggestion.source(sources[suggestion.File]); ok {
+			gutter := max(width, len(strconv.Itoa(suggestion.Line+len(lines)-1)))
+
+			view.Indent = strings.Repeat(" ", gutter)
+			view.Lines = suggestion.layout(lines, gutter)
+		}
+
+		result = append(result, view)
+	}
+
+	return result
+}
+
+// source returns the lines that the suggestion changes.
+// It returns false if the lines are not available or the span does not contain the original text.
+func (s Suggestion) source(file sourceFile) ([]string, bool) {
+	if s.Span < 0 {
+		return nil, false
+	}
+
+	first, ok := file.line(s.Line)
+	if !ok {
+		return nil, false
+	}
+
+	text := []rune(first)
+	start, end := s.Column-1, s.Column-1+s.Span
+
+	if start < 0 || start > len(text) {
+		return nil, false
+	}
+
+	for number := s.Line + 1; end > len(text); number++ {
+		line, ok := file.line(number)
+		if !ok {
+			return nil, false
+		}
+
+		text = append(append(text, '\n'), []rune(line)...)
+	}
+
+	if string(text[start:end]) != s.Original {
+		return nil, false
+	}
+
+	return strings.Split(string(text), "\n"), true
+}
+
+// layout renders the patched line with the replacement underlined if a single line changes,
+// otherwise the removed and the added lines.
+func (s Suggestion) layout(lines []string, width int) []suggestionLine {
+	text := []rune(strings.Join(lines, "\n"))
+	prefix, suffix := string(text[:s.Column-1]), string(text[s.Column-1+s.Span:])
+
+	number := func(i int) string {
+		return padLeft(strconv.Itoa(s.Line+i), width)
+	}
+
+	if len(lines) == 1 && s.Replacement != "" && !strings.Contains(s.Replacement, "\n") {
+		marker := "~"
+		if s.Original == "" {
+			marker = "+"
+		}
+
+		replacement := expandTabs(s.Replacement)
+
+		return []suggestionLine{
+			{
+				Number: number(0),
+				Marker: "|",
+				Segments: []snippetSegment{
+					{Text: " " + expandTabs(prefix), Style: styleNone},
+					{Text: replacement, Style: styleAdded},
+					{Text: expandTabs(suffix), Style: styleNone},
+				},
+			},
+			{
+				Number: strings.Repeat(" ", width),
+				Marker: "|",
+				Segments: []snippetSegment{
+					{Text: strings.Repeat(" ", displayColumn(prefix, s.Column)), Style: styleNone},
+					{Text: strings.Repeat(marker, stringWidth(replacement)), Style: styleAdded},
+				},
+			},
+		}
+	}
+
+	result := make([]suggestionLine, 0, 2*len(lines))
+
+	for i, line := range lines {
+		result = append(result, diffLine(number(i), "-", line, styleRemoved))
+	}
+
+	for i, line := range strings.Split(prefix+s.Replacement+suffix, "\n") {
+		result = append(result, diffLine(number(i), "+", line, styleAdded))
+	}
+
+	return result
+}
+
+func diffLine(number, marker, line, style string) suggestionLine {
+	result := suggestionLine{
+		Number: number,
+		Marker: marker,
+	}
+
+	if line != "" {
+		result.Segments = []snippetSegment{{Text: " " + expandTabs(line), Style: style}}
+	}
+
+	return result
+}
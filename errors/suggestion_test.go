@@ -0,0 +1,152 @@
+package errors
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Suggestions(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	for _, tt := range []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name: "insertion",
+			err: New("'Foo' is not an iterator").
+				Code(277).
+				Snippet("src/main.rs", 3, iteratorSource).
+				Label("src/main.rs", 4, 16, 3, "'Foo' is not an iterator").
+				Suggest(Label{File: "src/main.rs", Line: 4, Column: 19, Text: "call '.iter()'"}, "", ".iter()"),
+			expected: `error[E0277]: 'Foo' is not an iterator
+  --> src/main.rs:4:16
+   |
+ 4 |     for foo in Foo {}
+   |                ^^^ 'Foo' is not an iterator
+   |
+help: call '.iter()'
+   |
+ 4 |     for foo in Foo.iter() {}
+   |                   +++++++`,
+		},
+		{
+			name: "replacement, tabs are expanded",
+			err: New("test").
+				Snippet("src/main.rs", 3, iteratorSource).
+				Suggest(Label{File: "src/main.rs", Line: 5, Column: 10}, "bar", "baz"),
+			expected: `error: test
+help: try this
+   |
+ 5 |     let x = baz(a, b);
+   |             ~~~`,
+		},
+		{
+			name: "deletion",
+			err: New("test").
+				Snippet("src/main.rs", 3, iteratorSource).
+				Suggest(Label{File: "src/main.rs", Line: 5, Column: 14, Text: "remove the argument"}, "a, ", ""),
+			expected: `error: test
+help: remove the argument
+   |
+ 5 -     let x = bar(a, b);
+ 5 +     let x = bar(b);`,
+		},
+		{
+			name: "multiple lines",
+			err: New("test").
+				Snippet("src/main.rs", 1, functionSource).
+				Suggest(Label{File: "src/main.rs", Line: 2, Column: 5}, "if true {\n        1\n    }", "1"),
+			expected: `error: test
+help: try this
+   |
+ 2 -     if true {
+ 3 -         1
+ 4 -     }
+ 2 +     1`,
+		},
+		{
+			name: "without source",
+			err: New("test").
+				Suggest(Label{File: "src/main.rs", Line: 2, Column: 5, Text: "use"}, "foo", "bar").
+				Suggest(Label{File: "src/main.rs", Line: 3, Column: 1}, "foo", ""),
+			expected: `error: test
+   = help: use: ` + "`bar`" + `
+   = help: try this: remove ` + "`foo`",
+		},
+		{
+			name: "the original text does not match the source",
+			err: New("test").
+				Snippet("src/main.rs", 3, iteratorSource).
+				Suggest(Label{File: "src/main.rs", Line: 5, Column: 10}, "foo", "baz"),
+			expected: `error: test
+   = help: try this: ` + "`baz`",
+		},
+		{
+			name: "negative span",
+			err: New("test").
+				Snippet("a.go", 1, "let x = 1").
+				Suggest(Label{File: "a.go", Line: 1, Column: 5, Span: -3}, "", "y"),
+			expected: `error: test
+   = help: try this: ` + "`y`",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.err.Error())
+		})
+	}
+}
+
+func Test_Suggestions_JSON(t *testing.T) {
+	err := New("test").
+		SuggestWithApplicability(Label{File: "src/main.rs", Line: 4, Column: 19}, "", ".iter()", ApplicabilityMachineApplicable)
+
+	data, marshalErr := json.Marshal(err)
+	require.NoError(t, marshalErr)
+
+	assert.JSONEq(t, `{
+		"type": "diagnostic",
+		"level": "error",
+		"message": "test",
+		"suggestions": [{
+			"message": "try this",
+			"file": "src/main.rs",
+			"line": 4,
+			"column": 19,
+			"span": 0,
+			"original": "",
+			"replacement": ".iter()",
+			"applicability": "MachineApplicable"
+		}]
+	}`, string(data))
+
+	var result Error
+	require.NoError(t, json.Unmarshal(data, &result))
+	assert.Equal(t, err.Suggestions(), result.Suggestions())
+}
+
+func Test_Applicability_Text(t *testing.T) {
+	for _, applicability := range []Applicability{
+		ApplicabilityUnspecified,
+		ApplicabilityMachineApplicable,
+		ApplicabilityMaybeIncorrect,
+		ApplicabilityHasPlaceholders,
+	} {
+		text, err := applicability.MarshalText()
+		require.NoError(t, err)
+
+		var result Applicability
+		require.NoError(t, result.UnmarshalText(text))
+		assert.Equal(t, applicability, result)
+	}
+
+	_, err := Applicability(42).MarshalText()
+	assert.Error(t, err)
+}
@@ -1,7 +1,10 @@
 package errors
 
 import (
+	"maps"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"text/template"
 
 	"github.com/fatih/color"
@@ -10,18 +13,33 @@ import (
 const (
 	templateNameError = "error"
 
-	funcBold      = "bold"
-	funcBoldRed   = "boldRed"
-	funcBoldBlue  = "boldBlue"
-	funcBoldGreen = "boldGreen"
-	funcSplit     = "split"
-
-	dataMessage = "Message"
-	dataWrapped = "Wrapped"
-	dataCode    = "Code"
-	dataCause   = "Cause"
-	dataNotes   = "Notes"
-	dataHelps   = "Helps"
+	funcBold       = "bold"
+	funcBoldRed    = "boldRed"
+	funcBoldBlue   = "boldBlue"
+	funcBoldGreen  = "boldGreen"
+	funcBoldYellow = "boldYellow"
+	funcBoldCyan   = "boldCyan"
+	funcSeverity   = "severityColor"
+	funcBug        = "isBug"
+	funcSplit      = "split"
+	funcRender     = "render"
+	funcWrap       = "wrap"
+
+	dataMessage     = "Message"
+	dataWrapped     = "Wrapped"
+	dataCode        = "Code"
+	dataSeverity    = "Severity"
+	dataCause       = "Cause"
+	dataTruncated   = "Truncated"
+	dataSuggestions = "Suggestions"
+	dataNotes       = "Notes"
+	dataHelps       = "Helps"
+	// dataIndent is the width of the rendered message prefix and its separator
+	dataIndent = "Indent"
+
+	dataSnippets   = "Snippets"
+	dataStack      = "Stack"
+	dataParameters = "Parameters"
 )
 
 type TemplateDefinition string
@@ -31,26 +49,74 @@ const (
 	TemplateDefinitionMessagePrefix TemplateDefinition = "messagePrefix"
 	TemplateDefinitionNotes         TemplateDefinition = "notes"
 	TemplateDefinitionHelps         TemplateDefinition = "helps"
+	TemplateDefinitionSnippets      TemplateDefinition = "snippets"
+	TemplateDefinitionStack         TemplateDefinition = "stack"
+	TemplateDefinitionWrapped       TemplateDefinition = "wrapped"
+	TemplateDefinitionSuggestions   TemplateDefinition = "suggestions"
+	// TemplateDefinitionMarkdown is the whole diagnostic in FormatMarkdown
+	TemplateDefinitionMarkdown TemplateDefinition = "markdown"
 )
 
 const (
-	errorTemplate = `{{- template "messagePrefix" . }}{{- bold (print ": " .Message) }}
+	errorTemplate = `{{- template "messagePrefix" . }}
+{{- $message := wrap .Indent .Message }}
+{{- bold (print ": " (index $message 0)) }}
+{{- range slice $message 1 }}
+{{ bold . }}
+{{- end }}
 {{- template "cause" . }}
 
+{{- template "snippets" . }}
+
 {{- template "notes" . }}
 
 {{- template "helps" . }}
 
-{{- if .Wrapped }}
+{{- template "suggestions" . }}
+
+{{- template "stack" . }}
+
+{{- template "wrapped" . }}`
+
+	wrappedTemplate = `{{- define "wrapped" }}
 {{- range .Wrapped }}
 
-{{ . }}
+{{ render . }}
+{{- end }}
+{{- if .Truncated }}
+{{- $errors := "errors" }}
+{{- if eq .Truncated 1 }}{{ $errors = "error" }}{{ end }}
+
+{{ boldBlue (print "... " .Truncated " more " $errors) }}
+{{- end }}
+{{- end }}`
+
+	// wrappedTreeTemplate renders the wrapped errors as the branches of a tree, see LayoutTree
+	wrappedTreeTemplate = `{{- define "wrapped" }}
+{{- range $i, $wrapped := .Wrapped }}
+{{- $lines := split (render $wrapped) "\n" }}
+{{- if eq (len (slice $.Wrapped $i)) 1 }}
+{{ boldBlue "╰─▶ " }}{{ index $lines 0 }}
+{{- range slice $lines 1 }}
+{{ if . }}    {{ . }}{{ end }}
+{{- end }}
+{{- else }}
+{{ boldBlue "├─▶ " }}{{ index $lines 0 }}
+{{- range slice $lines 1 }}
+{{ boldBlue "│" }}{{ if . }}   {{ . }}{{ end }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- if .Truncated }}
+{{- $errors := "errors" }}
+{{- if eq .Truncated 1 }}{{ $errors = "error" }}{{ end }}
+{{ boldBlue "╰─▶ " }}{{ boldBlue (print "... " .Truncated " more " $errors) }}
 {{- end }}
 {{- end }}`
 
 	causeTemplate = `{{- define "cause" }}
 {{- if .Cause }}
-{{- $cause := split (print .Cause) "\n" }}
+{{- $cause := split (render .Cause) "\n" }}
   {{ boldBlue "--> " -}}{{ index $cause 0 }}
   {{- range $line := slice $cause 1 }}
    {{ boldBlue "| " }}{{ . }}
@@ -58,18 +124,68 @@ const (
 {{- end }}
 {{- end }}`
 
+	snippetsTemplate = `{{- define "snippets" }}
+{{- range .Snippets }}
+{{ .Indent }}{{ boldBlue .Arrow }} {{ .Location }}
+{{- range .Lines }}
+{{ if .Gap }}{{ boldBlue "..." }}{{ else }}{{ boldBlue .Number }} {{ boldBlue "|" }}{{ end }}
+{{- range .Segments }}
+	{{- if eq .Style "primary" }}{{ severityColor $.Severity .Text }}
+	{{- else if eq .Style "secondary" }}{{ boldBlue .Text }}
+	{{- else }}{{ .Text }}
+	{{- end }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- end }}`
+
+	// suggestionsTemplate renders the suggestions with their source code as diffs,
+	// the others as helps
+	suggestionsTemplate = `{{- define "suggestions" }}
+{{- range .Suggestions }}
+{{- if .Lines }}
+{{ boldGreen "help" }}: {{ .Message }}
+{{ .Indent }} {{ boldBlue "|" }}
+{{- range .Lines }}
+{{ boldBlue .Number }} {{ if eq .Marker "+" }}{{ boldGreen .Marker }}{{ else if eq .Marker "-" }}{{ boldRed .Marker }}{{ else }}{{ boldBlue .Marker }}{{ end }}
+{{- range .Segments }}
+	{{- if eq .Style "added" }}{{ boldGreen .Text }}
+	{{- else if eq .Style "removed" }}{{ boldRed .Text }}
+	{{- else }}{{ .Text }}
+	{{- end }}
+{{- end }}
+{{- end }}
+{{- else if .Replacement }}
+   {{ boldBlue "= " }}{{ boldGreen "help" }}: {{ .Message }}: ` + "`{{ .Replacement }}`" + `
+{{- else }}
+   {{ boldBlue "= " }}{{ boldGreen "help" }}: {{ .Message }}: remove ` + "`{{ .Original }}`" + `
+{{- end }}
+{{- end }}
+{{- end }}`
+
+	stackTemplate = `{{- define "stack" }}
+{{- if .Stack }}
+   {{ boldBlue "= " }}{{ bold "stack" }}:
+   {{- range .Stack }}
+           {{ .Function }}
+               {{ .File }}:{{ .Line }}
+   {{- end }}
+{{- end }}
+{{- end }}`
+
+	// messagePrefixTemplate renders the code after the severity, the bugs are rendered without it like in rustc
 	messagePrefixTemplate = `{{- define "messagePrefix" }}
-	{{- if .Code }}
-		{{- boldRed (print "error[" .Code "]") }}
+	{{- if and .Code (not (isBug .Severity)) }}
+		{{- severityColor .Severity (print .Severity "[" .Code "]") }}
 	{{- else }}
-		{{- boldRed "error" }}
+		{{- severityColor .Severity (print .Severity) }}
 	{{- end }}
 {{- end }}`
 
 	notesTemplate = `{{- define "notes" }}
 {{- if .Notes }}
    {{- range $note := .Notes }}
-   {{- $lines := split $note "\n" }}
+   {{- $lines := wrap 11 $note }}
    {{ boldBlue "= " }}{{ bold "note" }}: {{ index $lines 0 -}}
        {{- range slice $lines 1 }}
            {{ . }}
@@ -81,7 +197,7 @@ const (
 	helpsTemplate = `{{- define "helps" }}
 {{- if .Helps }}
    {{- range $help := .Helps }}
-   {{- $lines := split $help "\n" }}
+   {{- $lines := wrap 11 $help }}
    {{ boldBlue "= " }}{{ boldGreen "help" }}: {{ index $lines 0 -}}
        {{- range slice $lines 1 }}
            {{ . }}
@@ -91,49 +207,138 @@ const (
 {{- end }}`
 )
 
+// palette has the colors of the templates, the colors of the plain palette return the text unchanged.
+type palette struct {
+	bold, boldRed, boldBlue, boldGreen, boldYellow, boldCyan func(format string, a ...any) string
+}
+
 var (
-	bold      = color.New(color.Bold).Sprintf
-	boldRed   = color.New(color.FgRed, color.Bold).Sprintf
-	boldBlue  = color.New(color.FgBlue, color.Bold).Sprintf
-	boldGreen = color.New(color.FgGreen, color.Bold).Sprintf
+	colorPalette = newPalette(true)
+	plainPalette = newPalette(false)
 )
 
-var funcMap = template.FuncMap{
-	funcBold:      bold,
-	funcBoldRed:   boldRed,
-	funcBoldGreen: boldGreen,
-	funcBoldBlue:  boldBlue,
-	funcSplit:     strings.Split,
+func newPalette(colored bool) palette {
+	paint := func(attributes ...color.Attribute) func(format string, a ...any) string {
+		c := color.New(attributes...)
+		if colored {
+			c.EnableColor()
+		} else {
+			c.DisableColor()
+		}
+
+		return c.Sprintf
+	}
+
+	return palette{
+		bold:       paint(color.Bold),
+		boldRed:    paint(color.FgRed, color.Bold),
+		boldBlue:   paint(color.FgBlue, color.Bold),
+		boldGreen:  paint(color.FgGreen, color.Bold),
+		boldYellow: paint(color.FgYellow, color.Bold),
+		boldCyan:   paint(color.FgCyan, color.Bold),
+	}
+}
+
+// funcMap returns the functions of the templates, the functions of the options replace them
+// except the internal ones that the templates depend on.
+func (p palette) funcMap(user template.FuncMap) template.FuncMap {
+	result := template.FuncMap{
+		funcBold:       p.bold,
+		funcBoldRed:    p.boldRed,
+		funcBoldGreen:  p.boldGreen,
+		funcBoldBlue:   p.boldBlue,
+		funcBoldYellow: p.boldYellow,
+		funcBoldCyan:   p.boldCyan,
+		funcSplit:      strings.Split,
+	}
+
+	maps.Copy(result, user)
+
+	maps.Copy(result, template.FuncMap{
+		funcSeverity: p.severityColor,
+		funcBug:      isBug,
+		funcMarkdown: markdownEscape,
+		// replaced by the copies of the templates that are bound to a renderer, see templatePool
+		funcRender:  func(err error) string { return err.Error() },
+		funcWrap:    func(indent int, text string) []string { return strings.Split(text, "\n") },
+		funcSection: func(err error) string { return err.Error() },
+	})
+
+	return result
 }
 
-var defaultInit *Init
+// defaultInit is used by New and Newf, it is replaced by the global setters
+// so that the errors can be rendered while the configuration changes.
+// It is initialized with the package variables so that errors can be defined by them as well.
+var (
+	defaultInit   = newDefaultInit()
+	defaultInitMu sync.Mutex
+)
+
+func newDefaultInit() *atomic.Pointer[Init] {
+	result := &atomic.Pointer[Init]{}
+	result.Store(NewInitializer())
 
-func init() {
-	Reset()
+	return result
 }
 
 func Reset() {
-	defaultInit = NewInitializer()
+	defaultInitMu.Lock()
+	defer defaultInitMu.Unlock()
+
+	defaultInit.Store(NewInitializer())
+}
+
+// updateDefaultInit replaces the default initializer with a copy that has the options applied.
+func updateDefaultInit(opts ...InitOption) {
+	defaultInitMu.Lock()
+	defer defaultInitMu.Unlock()
+
+	defaultInit.Store(defaultInit.Load().with(opts...))
 }
 
 func SetCauseTemplate(template string) {
-	defaultInit.definitions[TemplateDefinitionCause] = template
-	defaultInit.template = newTemplate(defaultInit.definitions, defaultInit.funcMap)
+	updateDefaultInit(WithTemplateDefinition(TemplateDefinitionCause, template))
 }
 
 func SetMessagePrefixTemplate(template string) {
-	defaultInit.definitions[TemplateDefinitionMessagePrefix] = template
-	defaultInit.template = newTemplate(defaultInit.definitions, defaultInit.funcMap)
+	updateDefaultInit(WithTemplateDefinition(TemplateDefinitionMessagePrefix, template))
 }
 
 func SetNotesTemplate(template string) {
-	defaultInit.definitions[TemplateDefinitionNotes] = template
-	defaultInit.template = newTemplate(defaultInit.definitions, defaultInit.funcMap)
+	updateDefaultInit(WithTemplateDefinition(TemplateDefinitionNotes, template))
 }
 
 func SetHelpsTemplate(template string) {
-	defaultInit.definitions[TemplateDefinitionHelps] = template
-	defaultInit.template = newTemplate(defaultInit.definitions, defaultInit.funcMap)
+	updateDefaultInit(WithTemplateDefinition(TemplateDefinitionHelps, template))
+}
+
+// SetStackTraceDepth enables capturing the call stack for the errors created by New and Newf,
+// 0 disables it.
+func SetStackTraceDepth(depth int) {
+	updateDefaultInit(WithStackTrace(depth))
+}
+
+// SetLayout changes how the wrapped errors of the errors created by New and Newf are rendered.
+func SetLayout(layout Layout) {
+	updateDefaultInit(WithLayout(layout))
+}
+
+// SetMaxDepth limits how deep the tree of the wrapped errors created by New and Newf is rendered,
+// 0 renders the whole tree.
+func SetMaxDepth(depth int) {
+	updateDefaultInit(WithMaxDepth(depth))
+}
+
+// SetRegistry sets the registry of the error codes for the errors created by New and Newf.
+func SetRegistry(registry *Registry) {
+	updateDefaultInit(WithRegistry(registry))
+}
+
+// SetCodeFormat changes how the numeric codes of the errors created by New and Newf are rendered.
+// It panics if the width is negative.
+func SetCodeFormat(prefix string, width int) {
+	updateDefaultInit(WithCodeFormat(prefix, width))
 }
 
 func AdditionalTemplateFunc(name string, fn any) {
@@ -141,14 +346,7 @@ func AdditionalTemplateFunc(name string, fn any) {
 }
 
 func AdditionalTemplateFuncs(funcs template.FuncMap) {
-	functions := defaultInit.funcMap
-
-	for name, fn := range funcs {
-		functions[name] = fn
-	}
-
-	defaultInit.funcMap = functions
-	defaultInit.template = newTemplate(defaultInit.definitions, functions)
+	updateDefaultInit(WithAdditionalFunctions(funcs))
 }
 
 func newTemplate(definitions map[TemplateDefinition]string, fns template.FuncMap) *template.Template {
@@ -0,0 +1,226 @@
+package errors
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mattn/go-isatty"
+)
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// minWrapWidth is the narrowest column the text is wrapped to after the indentation.
+const minWrapWidth = 20
+
+// width returns the column at which the output of the writer is wrapped, 0 disables the wrapping.
+// Only terminals are wrapped by default, COLUMNS overrides the detected width of the terminal.
+func (o RenderOptions) width(w io.Writer) int {
+	// the markup formats are not displayed by the terminal
+	if o.Width != 0 || o.Format != FormatText {
+		return max(o.Width, 0)
+	}
+
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok || !isatty.IsTerminal(f.Fd()) {
+		return 0
+	}
+
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+
+	return terminalWidth(f.Fd())
+}
+
+// wrapText splits the text on the explicit line breaks and wraps the lines
+// so that they fit in width columns after the indentation. Width 0 disables the wrapping.
+func wrapText(text string, indent, width int) []string {
+	lines := strings.Split(text, "\n")
+	if width <= 0 {
+		return lines
+	}
+
+	width = max(width-indent, minWrapWidth)
+
+	result := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		result = append(result, wrapLine(line, width)...)
+	}
+
+	return result
+}
+
+// wrapLine breaks the line at the whitespace between the words, the whitespace at the breaks is dropped
+// and the rest is kept. The continuation lines keep the indentation of the line.
+// Words that are wider than the line are broken between their characters.
+func wrapLine(line string, width int) []string {
+	if tabbedWidth(line) <= width {
+		return []string{line}
+	}
+
+	words := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(words)]
+	width -= tabbedWidth(indent)
+
+	var (
+		result  []string
+		current strings.Builder
+		used    int
+		// space is the whitespace before the next word
+		space string
+	)
+
+	flush := func() {
+		result = append(result, indent+current.String())
+		current.Reset()
+		used = 0
+	}
+
+	for _, token := range splitWords(words) {
+		if strings.TrimSpace(token) == "" {
+			space = token
+			continue
+		}
+
+		wordWidth := stringWidth(token)
+		spaceWidth := tabbedWidth(space)
+
+		if used > 0 && used+spaceWidth+wordWidth > width {
+			flush()
+		}
+
+		if used > 0 {
+			current.WriteString(space)
+			used += spaceWidth
+		}
+
+		space = ""
+
+		if wordWidth <= width-used {
+			current.WriteString(token)
+			used += wordWidth
+
+			continue
+		}
+
+		for _, r := range token {
+			w := runeWidth(r)
+			if used > 0 && w > 0 && used+w > width {
+				flush()
+			}
+
+			current.WriteRune(r)
+			used += w
+		}
+	}
+
+	flush()
+
+	return result
+}
+
+// splitWords splits the text into the runs of whitespace and the words between them.
+func splitWords(text string) []string {
+	var result []string
+
+	start, space := 0, false
+	for i, r := range text {
+		if i > start && unicode.IsSpace(r) != space {
+			result = append(result, text[start:i])
+			start = i
+		}
+
+		space = unicode.IsSpace(r)
+	}
+
+	if start < len(text) {
+		result = append(result, text[start:])
+	}
+
+	return result
+}
+
+// tabbedWidth returns the number of columns of the text, tabs take tabWidth columns.
+func tabbedWidth(s string) int {
+	return stringWidth(s) + strings.Count(s, "\t")*tabWidth
+}
+
+// stringWidth returns the number of columns the text takes in a terminal.
+func stringWidth(s string) int {
+	result := 0
+
+	for _, r := range ansiEscape.ReplaceAllString(s, "") {
+		result += runeWidth(r)
+	}
+
+	return result
+}
+
+// runeWidth returns the number of columns the character takes in a terminal:
+// combining and control characters take none, East Asian wide characters take two.
+func runeWidth(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, unicode.Cc):
+		return 0
+	case unicode.Is(wideCharacters, r):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// wideCharacters are the wide and fullwidth characters of the Unicode East Asian Width property.
+var wideCharacters = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x1100, Hi: 0x115f, Stride: 1},
+		{Lo: 0x231a, Hi: 0x231b, Stride: 1},
+		{Lo: 0x2329, Hi: 0x232a, Stride: 1},
+		{Lo: 0x23e9, Hi: 0x23ec, Stride: 1},
+		{Lo: 0x25fd, Hi: 0x25fe, Stride: 1},
+		{Lo: 0x2614, Hi: 0x2615, Stride: 1},
+		{Lo: 0x2648, Hi: 0x2653, Stride: 1},
+		{Lo: 0x26aa, Hi: 0x26ab, Stride: 1},
+		{Lo: 0x26bd, Hi: 0x26be, Stride: 1},
+		{Lo: 0x26c4, Hi: 0x26c5, Stride: 1},
+		{Lo: 0x2705, Hi: 0x2705, Stride: 1},
+		{Lo: 0x270a, Hi: 0x270b, Stride: 1},
+		{Lo: 0x274c, Hi: 0x274c, Stride: 1},
+		{Lo: 0x2753, Hi: 0x2755, Stride: 1},
+		{Lo: 0x2795, Hi: 0x2797, Stride: 1},
+		{Lo: 0x2b1b, Hi: 0x2b1c, Stride: 1},
+		{Lo: 0x2e80, Hi: 0x303e, Stride: 1},
+		{Lo: 0x3041, Hi: 0x33ff, Stride: 1},
+		{Lo: 0x3400, Hi: 0x4dbf, Stride: 1},
+		{Lo: 0x4e00, Hi: 0x9fff, Stride: 1},
+		{Lo: 0xa000, Hi: 0xa4cf, Stride: 1},
+		{Lo: 0xa960, Hi: 0xa97f, Stride: 1},
+		{Lo: 0xac00, Hi: 0xd7a3, Stride: 1},
+		{Lo: 0xf900, Hi: 0xfaff, Stride: 1},
+		{Lo: 0xfe10, Hi: 0xfe19, Stride: 1},
+		{Lo: 0xfe30, Hi: 0xfe6f, Stride: 1},
+		{Lo: 0xff00, Hi: 0xff60, Stride: 1},
+		{Lo: 0xffe0, Hi: 0xffe6, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x16fe0, Hi: 0x16fe4, Stride: 1},
+		{Lo: 0x17000, Hi: 0x18cff, Stride: 1},
+		{Lo: 0x1b000, Hi: 0x1b2ff, Stride: 1},
+		{Lo: 0x1f004, Hi: 0x1f004, Stride: 1},
+		{Lo: 0x1f0cf, Hi: 0x1f0cf, Stride: 1},
+		{Lo: 0x1f18e, Hi: 0x1f18e, Stride: 1},
+		{Lo: 0x1f191, Hi: 0x1f19a, Stride: 1},
+		{Lo: 0x1f200, Hi: 0x1f251, Stride: 1},
+		{Lo: 0x1f300, Hi: 0x1f64f, Stride: 1},
+		{Lo: 0x1f680, Hi: 0x1f6ff, Stride: 1},
+		{Lo: 0x1f7e0, Hi: 0x1f7eb, Stride: 1},
+		{Lo: 0x1f90c, Hi: 0x1f9ff, Stride: 1},
+		{Lo: 0x1fa70, Hi: 0x1faff, Stride: 1},
+		{Lo: 0x20000, Hi: 0x2fffd, Stride: 1},
+		{Lo: 0x30000, Hi: 0x3fffd, Stride: 1},
+	},
+}
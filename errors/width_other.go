@@ -0,0 +1,9 @@
+//go:build !unix
+
+package errors
+
+// terminalWidth returns 0 because the size of the terminal is not detected on this platform,
+// the COLUMNS environment variable can be used instead.
+func terminalWidth(uintptr) int {
+	return 0
+}
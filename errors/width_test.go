@@ -0,0 +1,132 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Wrap_Text(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		text     string
+		indent   int
+		width    int
+		expected []string
+	}{
+		{
+			name:     "no wrapping",
+			text:     "first line\nsecond line",
+			width:    0,
+			expected: []string{"first line", "second line"},
+		},
+		{
+			name:     "words",
+			text:     "the quick brown fox jumps over the lazy dog",
+			width:    20,
+			expected: []string{"the quick brown fox", "jumps over the lazy", "dog"},
+		},
+		{
+			name:     "indentation",
+			text:     "the quick brown fox jumps over the lazy dog",
+			indent:   10,
+			width:    40,
+			expected: []string{"the quick brown fox jumps over", "the lazy dog"},
+		},
+		{
+			name:     "continuation lines keep the indentation",
+			text:     "available values:\n  - the quick brown fox jumps over the lazy dog",
+			width:    24,
+			expected: []string{"available values:", "  - the quick brown fox", "  jumps over the lazy", "  dog"},
+		},
+		{
+			name:     "whitespace between the words is kept",
+			text:     "a  b\tc  the quick brown fox jumps",
+			width:    20,
+			expected: []string{"a  b\tc  the quick", "brown fox jumps"},
+		},
+		{
+			name:     "long words are broken",
+			text:     "see https://example.com/a/very/long/path",
+			width:    20,
+			expected: []string{"see", "https://example.com/", "a/very/long/path"},
+		},
+		{
+			name:     "wide characters",
+			text:     "これは日本語のとても長いエラーメッセージです",
+			width:    20,
+			expected: []string{"これは日本語のとても", "長いエラーメッセージ", "です"},
+		},
+		{
+			name:     "minimum width",
+			text:     "the quick brown fox jumps over the lazy dog",
+			indent:   30,
+			width:    40,
+			expected: []string{"the quick brown fox", "jumps over the lazy", "dog"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, wrapText(tt.text, tt.indent, tt.width))
+		})
+	}
+}
+
+func Test_String_Width(t *testing.T) {
+	for _, tt := range []struct {
+		text     string
+		expected int
+	}{
+		{text: "abc", expected: 3},
+		{text: "日本", expected: 4},
+		{text: "é", expected: 1},
+		{text: "\x1b[1mbold\x1b[0m", expected: 4},
+		{text: "🚀", expected: 2},
+	} {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.expected, stringWidth(tt.text))
+		})
+	}
+}
+
+func Test_Render_Width(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	err := New("the configuration file could not be loaded").
+		Code(1).
+		Note("the file is read from the working directory and from the home directory of the user").
+		Help("create the file or set the path with the --config flag")
+
+	assert.Equal(t, `error[E0001]: the configuration file could
+not be loaded
+   = note: the file is read from the
+           working directory and from the
+           home directory of the user
+   = help: create the file or set the path
+           with the --config flag`, Sprint(err, RenderOptions{Width: 42}))
+
+	assert.Equal(t, err.Error(), Sprint(err, RenderOptions{Width: -1}))
+	assert.Equal(t, err.Error(), Sprint(err, RenderOptions{}))
+}
+
+func Test_Render_Width_Message_Prefix(t *testing.T) {
+	original := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = original }()
+
+	init := NewInitializer(WithTemplateDefinition(TemplateDefinitionMessagePrefix, `{{- define "messagePrefix" }}
+	{{- print "[" .Severity "] (" .Code ")" }}
+{{- end }}`))
+
+	err := init.NewError("the configuration file could not be loaded").Code(1)
+
+	// the prefix and the separator take 17 columns, 25 are left for the message
+	assert.Equal(t, `[error] (E0001): the configuration file
+could not be loaded`, Sprint(err, RenderOptions{Width: 42}))
+
+	assert.Equal(t, `<pre class="diagnostic"><span class="error">error[<span class="code">E0001</span>]</span><span class="message">: the configuration file could
+not be loaded</span></pre>`, Sprint(New("the configuration file could not be loaded").Code(1),
+		RenderOptions{Format: FormatHTML, Width: 42}))
+}
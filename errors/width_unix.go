@@ -0,0 +1,15 @@
+//go:build unix
+
+package errors
+
+import "golang.org/x/sys/unix"
+
+// terminalWidth returns the number of columns of the terminal, 0 if it is unknown.
+func terminalWidth(fd uintptr) int {
+	size, err := unix.IoctlGetWinsize(int(fd), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0
+	}
+
+	return int(size.Col)
+}
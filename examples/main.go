@@ -8,29 +8,34 @@ import (
 	"github.com/bsido/go-errors/errors"
 )
 
+const rustSource = `fn main() {
+    for foo in Foo {}
+    for foo in "" {}
+}`
+
 func main() {
-	fmt.Print(errors.New("test"))
+	fmt.Printf("%+v", errors.New("test"))
 	fmt.Print("\n----\n")
 
-	fmt.Print(errors.New("test").Wrap(errors.New("wrapped plain error")))
+	fmt.Printf("%+v", errors.New("test").Wrap(errors.New("wrapped plain error")))
 	fmt.Print("\n----\n")
 
-	fmt.Print(errors.New("test").
+	fmt.Printf("%+v", errors.New("test").
 		Wrap(errors.New("wrapped").
 			Wrap(errors.New("wrapped plain error"))))
 	fmt.Print("\n----\n")
 
-	fmt.Print(errors.New("test").
+	fmt.Printf("%+v", errors.New("test").
 		Wrap(errors.New("wrapped")))
 	fmt.Print("\n----\n")
 
-	fmt.Print(errors.New("test").
+	fmt.Printf("%+v", errors.New("test").
 		Cause(goerrors.New("cause of the 1st error\nsecond line")).
 		Wrap(errors.New("wrapped").
 			Cause(goerrors.New("cause of the 2nd error\nsecond line"))))
 	fmt.Print("\n----\n")
 
-	fmt.Print(errors.New("test notes").
+	fmt.Printf("%+v", errors.New("test notes").
 		Cause(goerrors.New("cause \nsecond line")).
 		Note("this is because \nthis and this").
 		Note("also \nthat").
@@ -38,37 +43,33 @@ func main() {
 		Help("also \ndo that"))
 	fmt.Print("\n----\n")
 
-	fmt.Print(errors.New("with code").
+	fmt.Printf("%+v", errors.New("with code").
 		Cause(goerrors.New("cause \nsecond line")).
 		Code(404))
 	fmt.Print("\n----\n")
 
 	err := errors.New("with code")
-	fmt.Print(errors.New("test").
+	fmt.Printf("%+v", errors.New("test").
 		HelpIf("do this \nbecause the condition is true", helpIfErrorContainsSt(err, "with code")))
 	fmt.Print("\n----\n")
 
-	fmt.Print(errors.New("'Foo' is not an iterator").
+	fmt.Printf("%+v", errors.New("'Foo' is not an iterator").
 		Code(277).
-		Causef(`src/main.rs:4:16
-
-     for foo in Foo {}
-                ^^^ 'Foo' is not an iterator
-`).Note("maybe try calling '.iter()' or a similar method").
+		Snippet("src/main.rs", 3, rustSource).
+		Label("src/main.rs", 4, 16, 3, "'Foo' is not an iterator").
+		Note("maybe try calling '.iter()' or a similar method").
 		Help("the trait 'std::iter::Iterator' is not implemented for 'Foo'").
 		Note("required by 'std::iter::IntoIterator::into_iter'").
 		Wrap(errors.New("'&str' is not an iterator").
 			Code(277).
-			Causef(`src/main.rs:5:16
-
-	 for foo in "" {}
-				^^ '&str' is not an iterator
-`).Help("call '.chars()' or '.bytes() on '&str'").
+			Snippet("src/main.rs", 3, rustSource).
+			Label("src/main.rs", 5, 16, 2, "'&str' is not an iterator").
+			Help("call '.chars()' or '.bytes() on '&str'").
 			Help("the trait 'std::iter::Iterator' is not implemented for '&str'").
 			Note("required by 'std::iter::IntoIterator::into_iter'")))
 	fmt.Print("\n----\n")
 
-	fmt.Print(errors.New("this is the main message").
+	fmt.Printf("%+v", errors.New("this is the main message").
 		Note("this is the 1st note\nthis is the second line of the first note").
 		Note("this is the 2nd note\nthis is the second line of the second note"))
 
@@ -77,14 +78,14 @@ func main() {
 	other := errors.New("second").
 		Note("this might be happening because")
 
-	fmt.Print(errors.New("first").
+	fmt.Printf("%+v", errors.New("first").
 		Help("do this!").
 		Wrap(other))
 	fmt.Print("\n----\n")
 
 	if err := callFunction(); err != nil {
 		// print the error
-		fmt.Print(err)
+		fmt.Printf("%+v", err)
 	}
 }
 
@@ -3,41 +3,19 @@ package warnings
 import (
 	goerrors "errors"
 	"fmt"
-	"strings"
-	"text/template"
 
 	"github.com/bsido/go-errors/errors"
-	"github.com/fatih/color"
-)
-
-const (
-	funcBoldYellow = "boldYellow"
-
-	messagePrefixTemplate = `{{- define "messagePrefix" }}
-	{{- if .Code }}
-		{{- boldYellow (print "warning[" .Code "]") }}
-	{{- else }}
-		{{- boldYellow "warning" }}
-	{{- end }}
-{{- end }}`
-)
-
-var (
-	boldYellow = color.New(color.FgYellow, color.Bold).Sprintf
 )
 
 var warningsInit *errors.Init
 
 func init() {
-	warningsInit = errors.NewInitializer(
-		errors.WithTemplateDefinition(errors.TemplateDefinitionMessagePrefix, messagePrefixTemplate),
-		errors.WithAdditionalFunctions(template.FuncMap{
-			funcBoldYellow: boldYellow,
-		}))
+	// warning codes are in their own namespace: W0001
+	warningsInit = errors.NewInitializer(errors.WithCodeFormat("W", 4))
 }
 
 func New(message string) *errors.Error {
-	return warningsInit.NewError(message)
+	return warningsInit.NewError(message).SetSeverity(errors.SeverityWarning)
 }
 
 func Newf(format string, args ...any) *errors.Error {
@@ -47,24 +25,29 @@ func Newf(format string, args ...any) *errors.Error {
 func From(err error) *errors.Error {
 	var e *errors.Error
 	if goerrors.As(err, &e) {
-		return warningsInit.Extend(e)
+		return warningsInit.Extend(e).SetSeverity(errors.SeverityWarning)
 	}
 
 	return New(err.Error())
 }
 
-// Is returns true if the error is a warning
-func Is(original error) bool {
-	var err *errors.Error
+// Is returns true if the error or any error in its wrapped errors or causes is a warning.
+func Is(err error) bool {
+	return errors.IsSeverity(err, errors.SeverityWarning)
+}
 
-	ok := goerrors.As(original, &err)
-	if !ok {
-		return false
-	}
+// As finds the first warning in the tree of err and sets target to it.
+func As(err error, target **errors.Error) bool {
+	found := false
 
-	if strings.Contains(strings.SplitN(err.Error(), "\n", 2)[0], "warning") {
-		return true
-	}
+	errors.Walk(err, func(e *errors.Error) bool {
+		if e.Severity() == errors.SeverityWarning {
+			*target = e
+			found = true
+		}
+
+		return !found
+	})
 
-	return false
+	return found
 }
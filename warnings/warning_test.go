@@ -1,6 +1,8 @@
 package warnings
 
 import (
+	goerrors "errors"
+	"fmt"
 	"testing"
 
 	"github.com/bsido/go-errors/errors"
@@ -34,6 +36,11 @@ func Test_Basic_Usage(t *testing.T) {
 			expected: `warning: wrapper
   --> error: error`,
 		},
+		{
+			name:     "warning code",
+			err:      New("test").Code(1),
+			expected: "warning[W0001]: test",
+		},
 		{
 			name: "warning as a wrapper",
 			err:  New("wrapper").Wrap(errors.New("error")),
@@ -49,9 +56,81 @@ error: error`,
 }
 
 func Test_Is(t *testing.T) {
-	warning := New("test")
-	assert.True(t, Is(warning))
+	for _, tt := range []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "warning",
+			err:      New("test"),
+			expected: true,
+		},
+		{
+			name:     "warning with error cause",
+			err:      New("wrapper").Cause(errors.New("error")),
+			expected: true,
+		},
+		{
+			name:     "warning from an error",
+			err:      From(errors.New("error")),
+			expected: true,
+		},
+		{
+			name:     "warning from a plain error",
+			err:      From(goerrors.New("error")),
+			expected: true,
+		},
+		{
+			name:     "error",
+			err:      errors.New("error"),
+			expected: false,
+		},
+		{
+			name:     "error with 'warning' in the message",
+			err:      errors.New("warning: this is not a warning"),
+			expected: false,
+		},
+		{
+			name:     "plain error",
+			err:      goerrors.New("warning"),
+			expected: false,
+		},
+		{
+			name:     "wrapped warning",
+			err:      errors.New("error").Wrap(New("warning")),
+			expected: true,
+		},
+		{
+			name:     "warning as the cause",
+			err:      errors.New("error").Cause(fmt.Errorf("wrapped: %w", New("warning"))),
+			expected: true,
+		},
+		{
+			name: "custom message prefix template",
+			err: errors.NewInitializer(
+				errors.WithTemplateDefinition(errors.TemplateDefinitionMessagePrefix,
+					`{{- define "messagePrefix" }}W{{ end }}`)).
+				NewError("test").
+				SetSeverity(errors.SeverityWarning),
+			expected: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Is(tt.err))
+		})
+	}
+}
+
+func Test_As(t *testing.T) {
+	warning := New("first warning")
+
+	var target *errors.Error
+
+	assert.True(t, As(errors.New("error").Wrap(warning).Wrap(New("second warning")), &target))
+	assert.Same(t, warning, target)
 
-	warning = New("wrapper").Cause(errors.New("error"))
-	assert.True(t, Is(warning))
+	target = nil
+	assert.False(t, As(errors.New("error").Wrap(errors.New("other")), &target))
+	assert.Nil(t, target)
 }